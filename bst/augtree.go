@@ -0,0 +1,141 @@
+package bst
+
+import "iter"
+
+// AugData is the data of a node in an AugTree,
+// which holds a value and the aggregate of the subtree of the node.
+type AugData[T, A any] struct {
+	Value T
+	agg   A
+}
+
+// Agg returns the aggregate of the subtree of the node holding d.
+func (d AugData[T, A]) Agg() A {
+	return d.agg
+}
+
+// AugTree is a red-black tree that keeps an aggregate of every subtree,
+// such as subtree sums, minimums or maximum endpoints of intervals.
+//
+// The aggregate of a subtree is augment(v, left, right), where v is the
+// value of its root, and left and right are the aggregates of its children.
+// An empty subtree has the aggregate identity. For Query to fold values in
+// order, augment(v, l, r) must be the combination of l, v and r under an
+// associative operation with identity as its identity element.
+//
+// It must be initialized by Init before use.
+type AugTree[T, A any] struct {
+	tree     RBTree[AugData[T, A]]
+	less     func(a, b T) bool
+	augment  func(v T, left, right A) A
+	identity A
+}
+
+// Init empties t and sets up its order and aggregate.
+func (t *AugTree[T, A]) Init(less func(a, b T) bool, augment func(v T, left, right A) A, identity A) {
+	t.less, t.augment, t.identity = less, augment, identity
+	t.tree = RBTree[AugData[T, A]]{}
+	t.tree.Init(func(a, b AugData[T, A]) bool {
+		return less(a.Value, b.Value)
+	})
+	t.tree.Nil.Data.agg = identity
+	t.tree.augment = func(x *RBNode[AugData[T, A]]) {
+		x.Data.agg = augment(x.Data.Value, x.left.Data.agg, x.right.Data.agg)
+	}
+}
+
+// Tree returns the underlying red-black tree, which keeps the aggregates
+// up to date on its own. The values in its nodes must not be changed.
+func (t *AugTree[T, A]) Tree() *RBTree[AugData[T, A]] {
+	return &t.tree
+}
+
+// Len returns the number of values in t.
+func (t *AugTree[T, A]) Len() int {
+	return t.tree.Len()
+}
+
+// Insert v into t and return its node.
+//
+// Actual cost is O(lg n) calls of augment.
+func (t *AugTree[T, A]) Insert(v T) *RBNode[AugData[T, A]] {
+	return t.tree.Insert(AugData[T, A]{Value: v})
+}
+
+// Delete the node z from t.
+//
+// Actual cost is O(lg n) calls of augment.
+func (t *AugTree[T, A]) Delete(z *RBNode[AugData[T, A]]) {
+	t.tree.Delete(z)
+}
+
+// Find returns the first node holding a value equal to v,
+// or nil if there is no such node.
+func (t *AugTree[T, A]) Find(v T) *RBNode[AugData[T, A]] {
+	return t.tree.Find(AugData[T, A]{Value: v})
+}
+
+// DeleteValue deletes a node holding a value equal to v,
+// and returns false if there is no such node.
+func (t *AugTree[T, A]) DeleteValue(v T) bool {
+	return t.tree.DeleteValue(AugData[T, A]{Value: v})
+}
+
+// All returns an iterator over the values of t in order.
+func (t *AugTree[T, A]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for d := range t.tree.All() {
+			if !yield(d.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Aggregate returns the aggregate of all values in t.
+func (t *AugTree[T, A]) Aggregate() A {
+	return t.tree.Root.Data.agg
+}
+
+// Query returns the aggregate of the values v in t with lo <= v < hi.
+//
+// Actual cost is O(lg n) calls of augment.
+func (t *AugTree[T, A]) Query(lo, hi T) A {
+	// find the highest node in the range, which splits the range in two
+	x := t.tree.Root
+	for x != t.tree.Nil {
+		if t.less(x.Data.Value, lo) {
+			x = x.right
+		} else if !t.less(x.Data.Value, hi) {
+			x = x.left
+		} else {
+			break
+		}
+	}
+	if x == t.tree.Nil {
+		return t.identity
+	}
+	return t.augment(x.Data.Value, t.suffix(x.left, lo), t.prefix(x.right, hi))
+}
+
+// suffix returns the aggregate of the values v in the subtree of x with lo <= v.
+func (t *AugTree[T, A]) suffix(x *RBNode[AugData[T, A]], lo T) A {
+	if x == t.tree.Nil {
+		return t.identity
+	}
+	if t.less(x.Data.Value, lo) {
+		return t.suffix(x.right, lo)
+	}
+	return t.augment(x.Data.Value, t.suffix(x.left, lo), x.right.Data.agg)
+}
+
+// prefix returns the aggregate of the values v in the subtree of x with v < hi.
+func (t *AugTree[T, A]) prefix(x *RBNode[AugData[T, A]], hi T) A {
+	if x == t.tree.Nil {
+		return t.identity
+	}
+	if !t.less(x.Data.Value, hi) {
+		return t.prefix(x.left, hi)
+	}
+	return t.augment(x.Data.Value, x.left.Data.agg, t.prefix(x.right, hi))
+}
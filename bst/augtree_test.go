@@ -0,0 +1,115 @@
+package bst
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newConcatTree returns an AugTree aggregating its values by concatenation,
+// which is associative but not commutative, so the order of folding shows.
+func newConcatTree() *AugTree[int, string] {
+	t := &AugTree[int, string]{}
+	t.Init(cmp.Less[int], func(v int, left, right string) string {
+		return left + strconv.Itoa(v) + "," + right
+	}, "")
+	return t
+}
+
+func bruteConcat(values []int, lo, hi int) string {
+	s := ""
+	for _, v := range values {
+		if lo <= v && v < hi {
+			s += strconv.Itoa(v) + ","
+		}
+	}
+	return s
+}
+
+// checkAgg checks that the aggregate of every node of t is up to date.
+func checkAgg[T, A comparable](t *testing.T, at *AugTree[T, A]) {
+	t.Helper()
+	for x := range at.tree.Nodes() {
+		if want := at.augment(x.Data.Value, x.left.Data.agg, x.right.Data.agg); x.Data.agg != want {
+			t.Fatalf("got: %v, expect: %v at %v", x.Data.agg, want, x.Data.Value)
+		}
+	}
+}
+
+func TestAugTree_Sum(t *testing.T) {
+	at := &AugTree[int, int]{}
+	at.Init(cmp.Less[int], func(v, left, right int) int {
+		return left + v + right
+	}, 0)
+	for _, v := range []int{4, 1, 3, 5, 2} {
+		at.Insert(v)
+	}
+
+	if s := at.Aggregate(); s != 15 {
+		t.Fatalf("got: %d, expect: %d", s, 15)
+	}
+	if s := at.Query(2, 5); s != 9 {
+		t.Fatalf("got: %d, expect: %d", s, 9)
+	}
+	if s := at.Query(6, 9); s != 0 {
+		t.Fatalf("got: %d, expect: %d", s, 0)
+	}
+	x := at.Find(3)
+	if want := x.left.Data.Agg() + 3 + x.right.Data.Agg(); x.Data.Agg() != want {
+		t.Fatalf("got: %d, expect: %d", x.Data.Agg(), want)
+	}
+}
+
+func TestAugTree_Random(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	at := newConcatTree()
+	var values []int
+	for i := 0; i < 2000; i++ {
+		v := rng.Intn(300)
+		switch op := rng.Intn(4); {
+		case op < 2 || len(values) == 0:
+			at.Insert(v)
+			j, _ := slices.BinarySearch(values, v)
+			values = slices.Insert(values, j, v)
+		case op == 2:
+			v = values[rng.Intn(len(values))]
+			at.Delete(at.Find(v))
+			j, _ := slices.BinarySearch(values, v)
+			values = slices.Delete(values, j, j+1)
+		default:
+			j, ok := slices.BinarySearch(values, v)
+			if ok {
+				values = slices.Delete(values, j, j+1)
+			}
+			if got := at.DeleteValue(v); got != ok {
+				t.Fatalf("DeleteValue(%d): got: %v, expect: %v", v, got, ok)
+			}
+		}
+		if at.Len() != len(values) {
+			t.Fatalf("got: %d, expect: %d", at.Len(), len(values))
+		}
+
+		lo := rng.Intn(320) - 10
+		hi := lo + rng.Intn(60)
+		if got, want := at.Query(lo, hi), bruteConcat(values, lo, hi); got != want {
+			t.Fatalf("Query(%d, %d): got: %q, expect: %q", lo, hi, got, want)
+		}
+		if got, want := at.Aggregate(), bruteConcat(values, -1, 301); got != want {
+			t.Fatalf("Aggregate: got: %q, expect: %q", got, want)
+		}
+
+		if i%100 == 0 {
+			checkAgg(t, at)
+			checkRBTree(t, &at.tree)
+			if got := slices.Collect(at.All()); !slices.Equal(got, values) {
+				t.Fatalf("got: %v, expect: %v", got, values)
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package bst
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+)
+
+// Interval is a closed interval [Lo, Hi] with a value.
+type Interval[K, V any] struct {
+	Lo, Hi K
+	Value  V
+}
+
+// maxEnd is the maximum endpoint of the intervals in a subtree,
+// ok is false for an empty subtree.
+type maxEnd[K any] struct {
+	hi K
+	ok bool
+}
+
+// IntervalHandle refers to an interval in an IntervalTree.
+type IntervalHandle[K, V any] struct {
+	node *RBNode[AugData[Interval[K, V], maxEnd[K]]]
+}
+
+// Interval returns the interval referred to by h.
+func (h IntervalHandle[K, V]) Interval() Interval[K, V] {
+	return h.node.Data.Value
+}
+
+// IntervalTree holds closed intervals, and finds the ones overlapping
+// a given interval. It is a red-black tree ordered by the low endpoints,
+// in which every subtree is augmented with its maximum high endpoint.
+type IntervalTree[K, V any] struct {
+	tree AugTree[Interval[K, V], maxEnd[K]]
+	less func(a, b K) bool
+}
+
+// NewIntervalTree returns an empty IntervalTree ordered by the < operator of K.
+func NewIntervalTree[K cmp.Ordered, V any]() *IntervalTree[K, V] {
+	return NewIntervalTreeFunc[K, V](cmp.Less[K])
+}
+
+// NewIntervalTreeFunc returns an empty IntervalTree ordered by less.
+func NewIntervalTreeFunc[K, V any](less func(a, b K) bool) *IntervalTree[K, V] {
+	it := &IntervalTree[K, V]{less: less}
+	it.tree.Init(
+		func(a, b Interval[K, V]) bool {
+			return less(a.Lo, b.Lo)
+		},
+		func(v Interval[K, V], left, right maxEnd[K]) maxEnd[K] {
+			m := maxEnd[K]{v.Hi, true}
+			for _, c := range []maxEnd[K]{left, right} {
+				if c.ok && less(m.hi, c.hi) {
+					m = c
+				}
+			}
+			return m
+		},
+		maxEnd[K]{},
+	)
+	return it
+}
+
+// Len returns the number of intervals in it.
+func (it *IntervalTree[K, V]) Len() int {
+	return it.tree.Len()
+}
+
+// Insert the interval [lo, hi] with value into it and return its handle,
+// panic if hi is less than lo.
+//
+// Actual cost is O(lg n).
+func (it *IntervalTree[K, V]) Insert(lo, hi K, value V) IntervalHandle[K, V] {
+	if it.less(hi, lo) {
+		panic(fmt.Sprintf("invalid interval [%v, %v]", lo, hi))
+	}
+	return IntervalHandle[K, V]{it.tree.Insert(Interval[K, V]{lo, hi, value})}
+}
+
+// Delete the interval of h from it. h must not be used any more after the call.
+//
+// Actual cost is O(lg n).
+func (it *IntervalTree[K, V]) Delete(h IntervalHandle[K, V]) {
+	it.tree.Delete(h.node)
+}
+
+// All returns an iterator over the intervals of it in the order of
+// their low endpoints.
+func (it *IntervalTree[K, V]) All() iter.Seq[Interval[K, V]] {
+	return it.tree.All()
+}
+
+// overlaps returns whether the interval of x overlaps [lo, hi].
+func (it *IntervalTree[K, V]) overlaps(x *RBNode[AugData[Interval[K, V], maxEnd[K]]], lo, hi K) bool {
+	v := x.Data.Value
+	return !it.less(hi, v.Lo) && !it.less(v.Hi, lo)
+}
+
+// Overlapping returns an iterator over the intervals of it that overlap
+// [lo, hi], in the order of their low endpoints.
+// it must not be modified during the iteration.
+//
+// Actual cost is O(min(n, (k+1) lg n)), where k is the number of the yielded intervals.
+func (it *IntervalTree[K, V]) Overlapping(lo, hi K) iter.Seq[Interval[K, V]] {
+	return func(yield func(Interval[K, V]) bool) {
+		it.overlapping(it.tree.tree.Root, lo, hi, yield)
+	}
+}
+
+// overlapping yields the intervals overlapping [lo, hi] in the subtree of x,
+// and returns false if yield asks to stop.
+func (it *IntervalTree[K, V]) overlapping(x *RBNode[AugData[Interval[K, V], maxEnd[K]]], lo, hi K, yield func(Interval[K, V]) bool) bool {
+	// no interval in the subtree ends at or after lo
+	if x == it.tree.tree.Nil || it.less(x.Data.agg.hi, lo) {
+		return true
+	}
+
+	if !it.overlapping(x.left, lo, hi, yield) {
+		return false
+	}
+	// the intervals in the right subtree start after hi
+	if it.less(hi, x.Data.Value.Lo) {
+		return true
+	}
+	if it.overlaps(x, lo, hi) && !yield(x.Data.Value) {
+		return false
+	}
+	return it.overlapping(x.right, lo, hi, yield)
+}
+
+// Stabbing returns an iterator over the intervals of it that contain point,
+// in the order of their low endpoints.
+func (it *IntervalTree[K, V]) Stabbing(point K) iter.Seq[Interval[K, V]] {
+	return it.Overlapping(point, point)
+}
+
+// AnyOverlap returns an interval of it that overlaps [lo, hi],
+// or false if there is no such interval.
+//
+// Actual cost is O(lg n).
+func (it *IntervalTree[K, V]) AnyOverlap(lo, hi K) (Interval[K, V], bool) {
+	null := it.tree.tree.Nil
+	x := it.tree.tree.Root
+	for x != null && !it.overlaps(x, lo, hi) {
+		// if the left subtree has no overlapping interval even though
+		// one of them ends at or after lo, neither has the right subtree
+		if x.left != null && !it.less(x.left.Data.agg.hi, lo) {
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+
+	if x == null {
+		return Interval[K, V]{}, false
+	}
+	return x.Data.Value, true
+}
@@ -0,0 +1,134 @@
+package bst
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+// bruteOverlapping returns the intervals of ivs overlapping [lo, hi],
+// sorted by their values, which are unique.
+func bruteOverlapping(ivs []Interval[int, int], lo, hi int) []Interval[int, int] {
+	var res []Interval[int, int]
+	for _, iv := range ivs {
+		if iv.Lo <= hi && lo <= iv.Hi {
+			res = append(res, iv)
+		}
+	}
+	return sortIntervals(res)
+}
+
+func sortIntervals(ivs []Interval[int, int]) []Interval[int, int] {
+	slices.SortFunc(ivs, func(a, b Interval[int, int]) int {
+		return a.Value - b.Value
+	})
+	return ivs
+}
+
+func TestIntervalTree_Insert(t *testing.T) {
+	it := NewIntervalTree[int, string]()
+	h := it.Insert(3, 5, "a")
+	if iv := h.Interval(); iv.Lo != 3 || iv.Hi != 5 || iv.Value != "a" {
+		t.Fatalf("got: %v, expect: %v", iv, Interval[int, string]{3, 5, "a"})
+	}
+	if it.Len() != 1 {
+		t.Fatalf("got: %d, expect: %d", it.Len(), 1)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("should panic on an invalid interval")
+		}
+	}()
+	it.Insert(5, 3, "b")
+}
+
+func TestIntervalTree_Stabbing(t *testing.T) {
+	it := NewIntervalTree[int, int]()
+	for i, iv := range [][2]int{{1, 3}, {2, 6}, {5, 5}, {7, 9}, {0, 10}} {
+		it.Insert(iv[0], iv[1], i)
+	}
+
+	for point, want := range map[int][]int{
+		-1: nil,
+		2:  {4, 0, 1},
+		5:  {4, 1, 2},
+		9:  {4, 3},
+		11: nil,
+	} {
+		var got []int
+		for iv := range it.Stabbing(point) {
+			got = append(got, iv.Value)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("point %d: got: %v, expect: %v", point, got, want)
+		}
+	}
+}
+
+func TestIntervalTree_Random(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	it := NewIntervalTree[int, int]()
+	var ivs []Interval[int, int]
+	var handles []IntervalHandle[int, int]
+	for i := 0; i < 3000; i++ {
+		if rng.Intn(3) > 0 || len(ivs) == 0 {
+			lo := rng.Intn(1000)
+			hi := lo + rng.Intn(50)
+			handles = append(handles, it.Insert(lo, hi, i))
+			ivs = append(ivs, Interval[int, int]{lo, hi, i})
+		} else {
+			j := rng.Intn(len(ivs))
+			it.Delete(handles[j])
+			ivs[j], handles[j] = ivs[len(ivs)-1], handles[len(handles)-1]
+			ivs, handles = ivs[:len(ivs)-1], handles[:len(handles)-1]
+		}
+		if it.Len() != len(ivs) {
+			t.Fatalf("got: %d, expect: %d", it.Len(), len(ivs))
+		}
+
+		lo := rng.Intn(1100) - 50
+		hi := lo + rng.Intn(30)
+		want := bruteOverlapping(ivs, lo, hi)
+
+		got := slices.Collect(it.Overlapping(lo, hi))
+		if !slices.IsSortedFunc(got, func(a, b Interval[int, int]) int { return a.Lo - b.Lo }) {
+			t.Fatalf("intervals are not in order: %v", got)
+		}
+		if got = sortIntervals(got); !slices.Equal(got, want) {
+			t.Fatalf("[%d, %d]: got: %v, expect: %v", lo, hi, got, want)
+		}
+
+		iv, ok := it.AnyOverlap(lo, hi)
+		if ok != (len(want) > 0) {
+			t.Fatalf("[%d, %d]: got: %v, expect: %v", lo, hi, ok, len(want) > 0)
+		}
+		if ok && !slices.Contains(want, iv) {
+			t.Fatalf("[%d, %d]: %v does not overlap", lo, hi, iv)
+		}
+	}
+}
+
+func TestIntervalTree_OverlappingBreak(t *testing.T) {
+	it := NewIntervalTree[int, int]()
+	for i := 0; i < 100; i++ {
+		it.Insert(i, i+10, i)
+	}
+
+	n := 0
+	for iv := range it.Overlapping(20, 80) {
+		if iv.Value != 10+n {
+			t.Fatalf("got: %d, expect: %d", iv.Value, 10+n)
+		}
+		if n++; n == 5 {
+			break
+		}
+	}
+	if n != 5 {
+		t.Fatalf("got: %d, expect: %d", n, 5)
+	}
+}
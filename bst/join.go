@@ -0,0 +1,262 @@
+package bst
+
+import "fmt"
+
+// Split moves the values of rbt less than key to left, and the others to
+// right, leaving rbt empty. The nodes are moved rather than copied, so the
+// handles returned by Insert stay valid in the tree they are moved to.
+//
+// The new trees share the sentinel Nil with rbt, so that they can be joined
+// again in O(lg n). Since Delete writes to the sentinel, trees sharing it
+// must not be modified concurrently, unless Isolate gives them sentinels of
+// their own.
+//
+// Actual cost is O(lg n).
+func (rbt *RBTree[T]) Split(key T) (left, right *RBTree[T]) {
+	l, _, r, _ := rbt.split(rbt.Root, rbt.blackHeight(), key)
+	rbt.Root = rbt.Nil
+	return rbt.derive(l), rbt.derive(r)
+}
+
+// Join moves the values of right after the values of left, and returns left.
+// All values of left must not be greater than those of right, and left and
+// right must be different trees, or Join panics. right is left empty, and
+// the handles of its nodes stay valid in left.
+//
+// The O(lg n) bound only holds if left and right share the sentinel Nil,
+// that is, if they were produced by Split or Join from a common tree.
+// Otherwise the leaves of the smaller tree are relinked to the sentinel of
+// the other, and the cost is O(lg n + m) where m is the size of the smaller
+// tree. The result may thus share its sentinel with other trees, see Split.
+func Join[T any](left, right *RBTree[T]) *RBTree[T] {
+	if left == right {
+		panic("cannot join a tree with itself")
+	}
+	if right.Root == right.Nil {
+		return left
+	}
+	if left.Root == left.Nil {
+		left.Nil, left.Root = right.Nil, right.Root
+		right.Root = right.Nil
+		return left
+	}
+
+	left.shareNil(right)
+	lmax, rmin := left.max(left.Root), right.min(right.Root)
+	if left.Less(rmin.Data, lmax.Data) {
+		panic(fmt.Sprintf("cannot join: %v in left is greater than %v in right",
+			lmax.Data, rmin.Data))
+	}
+
+	// the minimum of right becomes the pivot
+	k := rmin
+	right.Delete(k)
+	left.Root, _ = left.join3(left.Root, left.blackHeight(), k, right.Root, right.blackHeight())
+	right.Root = right.Nil
+	return left
+}
+
+// Join3 is like Join, but also inserts pivot between the values of left and
+// right, and returns the node of pivot. pivot must not be less than the
+// values of left and greater than the values of right, and left and right
+// must be different trees, or Join3 panics.
+//
+// As with Join, the cost is O(lg n) only if left and right share the
+// sentinel Nil, otherwise O(lg n + m) where m is the size of the smaller tree.
+func Join3[T any](left *RBTree[T], pivot T, right *RBTree[T]) *RBNode[T] {
+	if left == right {
+		panic("cannot join a tree with itself")
+	}
+	if lmax := left.max(left.Root); lmax != left.Nil && left.Less(pivot, lmax.Data) {
+		panic(fmt.Sprintf("cannot join: %v in left is greater than pivot %v",
+			lmax.Data, pivot))
+	}
+	if rmin := right.min(right.Root); rmin != right.Nil && left.Less(rmin.Data, pivot) {
+		panic(fmt.Sprintf("cannot join: %v in right is less than pivot %v",
+			rmin.Data, pivot))
+	}
+
+	left.shareNil(right)
+	k := left.newRBNode(pivot)
+	left.Root, _ = left.join3(left.Root, left.blackHeight(), k, right.Root, right.blackHeight())
+	right.Root = right.Nil
+	return left.handOut(k)
+}
+
+// Isolate gives rbt a sentinel of its own, so that it can be modified
+// concurrently with the trees it was split from or joined with.
+//
+// Actual cost is O(n).
+func (rbt *RBTree[T]) Isolate() {
+	null := &RBNode[T]{Data: rbt.Nil.Data, color: BLACK}
+	null.left, null.right, null.parent = null, null, null
+	rbt.relinkNil(null)
+}
+
+// derive returns a tree with the given root,
+// which shares the sentinel and the settings of rbt.
+func (rbt *RBTree[T]) derive(root *RBNode[T]) *RBTree[T] {
+	return &RBTree[T]{
+		Nil:      rbt.Nil,
+		Root:     root,
+		Less:     rbt.Less,
+		pool:     rbt.pool,
+		parallel: rbt.parallel,
+		augment:  rbt.augment,
+	}
+}
+
+// shareNil makes rbt and t use the same sentinel,
+// by relinking the leaves of the smaller one to the sentinel of the other.
+func (rbt *RBTree[T]) shareNil(t *RBTree[T]) {
+	if rbt.Nil == t.Nil {
+		return
+	}
+	if rbt.Len() < t.Len() {
+		rbt.relinkNil(t.Nil)
+	} else {
+		t.relinkNil(rbt.Nil)
+	}
+}
+
+// relinkNil replaces the sentinel of rbt by null.
+func (rbt *RBTree[T]) relinkNil(null *RBNode[T]) {
+	if rbt.Root == rbt.Nil {
+		rbt.Root = null
+	} else {
+		rbt.Root.parent = null
+		rbt.relinkSubtree(rbt.Root, null)
+	}
+	rbt.Nil = null
+}
+
+func (rbt *RBTree[T]) relinkSubtree(x, null *RBNode[T]) {
+	if x.left == rbt.Nil {
+		x.left = null
+	} else {
+		rbt.relinkSubtree(x.left, null)
+	}
+	if x.right == rbt.Nil {
+		x.right = null
+	} else {
+		rbt.relinkSubtree(x.right, null)
+	}
+}
+
+// blackHeight returns the number of black nodes on a path
+// from the root of rbt to a leaf.
+func (rbt *RBTree[T]) blackHeight() int {
+	h := 0
+	for x := rbt.Root; x != rbt.Nil; x = x.left {
+		if x.color == BLACK {
+			h++
+		}
+	}
+	return h
+}
+
+// split splits the subtree of x with black height h into the nodes less
+// than key and the others, and returns the two trees with their black heights.
+func (rbt *RBTree[T]) split(x *RBNode[T], h int, key T) (*RBNode[T], int, *RBNode[T], int) {
+	if x == rbt.Nil {
+		return rbt.Nil, 0, rbt.Nil, 0
+	}
+
+	if x.color == BLACK {
+		h--
+	}
+	left, right := x.left, x.right
+	rbt.detach(left)
+	rbt.detach(right)
+
+	if rbt.Less(x.Data, key) {
+		l, lh, r, rh := rbt.split(right, h, key)
+		l, lh = rbt.join3(left, h, x, l, lh)
+		return l, lh, r, rh
+	}
+	l, lh, r, rh := rbt.split(left, h, key)
+	r, rh = rbt.join3(r, rh, x, right, h)
+	return l, lh, r, rh
+}
+
+// detach makes x the root of a separate tree.
+func (rbt *RBTree[T]) detach(x *RBNode[T]) {
+	if x != rbt.Nil {
+		x.parent = rbt.Nil
+	}
+}
+
+// join3 joins the trees rooted at l and r, with black heights lh and rh,
+// by the node k between them, and returns the new root and its black height.
+// The roots of l and r must have no parents.
+//
+// Actual cost is O(|lh - rh| + 1).
+func (rbt *RBTree[T]) join3(l *RBNode[T], lh int, k *RBNode[T], r *RBNode[T], rh int) (*RBNode[T], int) {
+	// a tree stays valid with its root turned black
+	if l.color == RED {
+		l.color = BLACK
+		lh++
+	}
+	if r.color == RED {
+		r.color = BLACK
+		rh++
+	}
+
+	if lh == rh {
+		k.left, k.right, k.parent, k.color = l, r, rbt.Nil, BLACK
+		rbt.link(k)
+		return k, lh + 1
+	}
+
+	// insert k as a red node in place of a black node y with the black
+	// height of the other tree, then fix up like an insertion
+	t := rbt.derive(l)
+	h := lh
+	if lh < rh {
+		t.Root, h = r, rh
+	}
+
+	p, y := rbt.Nil, t.Root
+	for y.color == RED || h > min(lh, rh) {
+		if y.color == BLACK {
+			h--
+		}
+		p = y
+		if lh > rh {
+			y = y.right
+		} else {
+			y = y.left
+		}
+	}
+
+	k.parent, k.color = p, RED
+	if lh > rh {
+		k.left, k.right = y, r
+		p.right = k
+	} else {
+		k.left, k.right = l, y
+		p.left = k
+	}
+	rbt.link(k)
+	for ; p != rbt.Nil; p = p.parent {
+		rbt.update(p)
+	}
+
+	t.insertFixup(k)
+	if t.Root.color == RED {
+		t.Root.color = BLACK
+		return t.Root, max(lh, rh) + 1
+	}
+	return t.Root, max(lh, rh)
+}
+
+// link sets k as the parent of its children, and updates k.
+func (rbt *RBTree[T]) link(k *RBNode[T]) {
+	if k.left != rbt.Nil {
+		k.left.parent = k
+	}
+	if k.right != rbt.Nil {
+		k.right.parent = k
+	}
+	rbt.update(k)
+}
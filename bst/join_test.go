@@ -0,0 +1,210 @@
+package bst
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// checkHandles checks that the handles still hold their items,
+// and that each of them is in the tree whose root is given by in.
+func checkHandles(t *testing.T, handles map[item]*RBNode[item], null *RBNode[item], in func(x item) *RBNode[item]) {
+	t.Helper()
+	for x, h := range handles {
+		if h.Data != x {
+			t.Fatalf("got: %v, expect: %v", h.Data, x)
+		}
+		root := h
+		for ; root.parent != null; root = root.parent {
+		}
+		if root != in(x) {
+			t.Fatalf("%v is not in the expected tree", x)
+		}
+	}
+}
+
+// randomItemTree returns a random tree of n items with keys less than
+// maxKey, its sorted items and their handles.
+func randomItemTree(rng *rand.Rand, n, maxKey int) (*RBTree[item], []item, map[item]*RBNode[item]) {
+	rbt := newItemTree()
+	var items []item
+	handles := map[item]*RBNode[item]{}
+	for i := 0; i < n; i++ {
+		x := item{rng.Intn(maxKey), i}
+		handles[x] = rbt.Insert(x)
+		items = insertItem(items, x)
+	}
+	return rbt, items, handles
+}
+
+func TestRBTree_SplitJoinRandom(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	for round := 0; round < 200; round++ {
+		rbt, items, handles := randomItemTree(rng, rng.Intn(300), 100)
+
+		k := rng.Intn(110) - 5
+		left, right := rbt.Split(item{key: k})
+		if rbt.Len() != 0 {
+			t.Fatalf("got: %d, expect: %d", rbt.Len(), 0)
+		}
+		j := 0
+		for j < len(items) && items[j].key < k {
+			j++
+		}
+		checkRBTree(t, left)
+		checkRBTree(t, right)
+		if got := slices.Collect(left.All()); !slices.Equal(got, items[:j]) {
+			t.Fatalf("left: got: %v, expect: %v", got, items[:j])
+		}
+		if got := slices.Collect(right.All()); !slices.Equal(got, items[j:]) {
+			t.Fatalf("right: got: %v, expect: %v", got, items[j:])
+		}
+		checkHandles(t, handles, left.Nil, func(x item) *RBNode[item] {
+			if x.key < k {
+				return left.Root
+			}
+			return right.Root
+		})
+
+		if rng.Intn(2) == 0 {
+			Join(left, right)
+		} else {
+			// the pivot is placed after the values of left equal to it
+			pivot := item{k, -1}
+			if j > 0 {
+				pivot.key = items[j-1].key
+			}
+			handles[pivot] = Join3(left, pivot, right)
+			items = slices.Insert(items, j, pivot)
+		}
+		if right.Len() != 0 {
+			t.Fatalf("got: %d, expect: %d", right.Len(), 0)
+		}
+		checkRBTree(t, left)
+		if got := slices.Collect(left.All()); !slices.Equal(got, items) {
+			t.Fatalf("got: %v, expect: %v", got, items)
+		}
+		checkHandles(t, handles, left.Nil, func(item) *RBNode[item] { return left.Root })
+
+		// the joined tree is still usable
+		for _, x := range items[:len(items)/2] {
+			left.Delete(handles[x])
+		}
+		checkRBTree(t, left)
+		if left.Len() != len(items)-len(items)/2 {
+			t.Fatalf("got: %d, expect: %d", left.Len(), len(items)-len(items)/2)
+		}
+	}
+}
+
+func TestRBTree_JoinSeparate(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	for round := 0; round < 100; round++ {
+		// the trees do not share their sentinels
+		left, litems, handles := randomItemTree(rng, rng.Intn(200), 100)
+		right, ritems, rhandles := randomItemTree(rng, rng.Intn(200), 100)
+		for x := range right.Nodes() {
+			x.Data.key += 100
+		}
+		for _, h := range rhandles {
+			handles[h.Data] = h
+		}
+		for i := range ritems {
+			ritems[i].key += 100
+		}
+
+		Join(left, right)
+		checkRBTree(t, left)
+		items := append(litems, ritems...)
+		if got := slices.Collect(left.All()); !slices.Equal(got, items) {
+			t.Fatalf("got: %v, expect: %v", got, items)
+		}
+		checkHandles(t, handles, left.Nil, func(item) *RBNode[item] { return left.Root })
+	}
+}
+
+func TestRBTree_JoinOrder(t *testing.T) {
+	for name, join := range map[string]func(l, r *RBTree[int]){
+		"Join":  func(l, r *RBTree[int]) { Join(l, r) },
+		"Join3": func(l, r *RBTree[int]) { Join3(l, 5, r) },
+	} {
+		l, r := newIntTree(), newIntTree()
+		l.Insert(6)
+		r.Insert(4)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s should panic on unordered trees", name)
+				}
+			}()
+			join(l, r)
+		}()
+	}
+}
+
+func TestRBTree_JoinSelf(t *testing.T) {
+	for name, join := range map[string]func(rbt *RBTree[int]){
+		"Join":  func(rbt *RBTree[int]) { Join(rbt, rbt) },
+		"Join3": func(rbt *RBTree[int]) { Join3(rbt, 5, rbt) },
+	} {
+		rbt := newIntTree()
+		for i := 0; i < 5; i++ {
+			rbt.Insert(5)
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s should panic on joining a tree with itself", name)
+				}
+			}()
+			join(rbt)
+		}()
+		checkRBTree(t, rbt)
+		if rbt.Len() != 5 {
+			t.Fatalf("got: %d, expect: %d", rbt.Len(), 5)
+		}
+	}
+}
+
+func TestRBTree_Isolate(t *testing.T) {
+	rbt := newIntTree()
+	for i := 0; i < 2000; i++ {
+		rbt.Insert(i)
+	}
+	left, right := rbt.Split(1000)
+	left.Isolate()
+	if left.Nil == right.Nil {
+		t.Fatal("left should have a sentinel of its own")
+	}
+	checkRBTree(t, left)
+
+	// the halves can be modified concurrently
+	var wg sync.WaitGroup
+	for _, half := range []*RBTree[int]{left, right} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range half.All() {
+				if v%2 == 0 {
+					half.DeleteValue(v)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	checkRBTree(t, left)
+	checkRBTree(t, right)
+	Join(left, right)
+	if left.Len() != 1000 {
+		t.Fatalf("got: %d, expect: %d", left.Len(), 1000)
+	}
+}
@@ -2,9 +2,11 @@ package bst
 
 import (
 	"fmt"
+	"iter"
 	"math"
 	"strings"
 
+	"github.com/25349023/datastruct/internal/pool"
 	"github.com/gookit/color"
 )
 
@@ -12,6 +14,13 @@ type RBTree[T any] struct {
 	Nil  *RBNode[T]
 	Root *RBNode[T]
 	Less func(a, b T) bool
+
+	pool     *pool.Pool[RBNode[T]]
+	parallel bool
+
+	// augment recomputes the augmented data of a node from its children,
+	// it is called whenever the subtree of the node changes.
+	augment func(x *RBNode[T])
 }
 
 func (rbt *RBTree[T]) Init(less func(a, b T) bool) {
@@ -23,22 +32,56 @@ func (rbt *RBTree[T]) Init(less func(a, b T) bool) {
 	rbt.Less = less
 }
 
+// Equal reports whether a and b hold equal values under rbt.Less,
+// that is, neither of them is less than the other.
 func (rbt *RBTree[T]) Equal(a, b RBNode[T]) bool {
-	return !rbt.Less(a.Data, b.Data) && !rbt.Less(b.Data, b.Data)
+	return !rbt.Less(a.Data, b.Data) && !rbt.Less(b.Data, a.Data)
+}
+
+// SetPooled enables or disables the pooled mode of rbt.
+//
+// In pooled mode, nodes are allocated in blocks, and the nodes deleted by
+// DeleteValue are reused by later insertions, unless they have been handed
+// out. A node is handed out once a method returns it to the user, such as
+// Insert, Find, Min or Nodes, and it is never reused after that, so a
+// deleted handle cannot be mistaken for a live one. Use Push to insert
+// values whose nodes may be reused.
+func (rbt *RBTree[T]) SetPooled(pooled bool) {
+	if !pooled {
+		rbt.pool = nil
+	} else if rbt.pool == nil {
+		rbt.pool = &pool.Pool[RBNode[T]]{}
+	}
 }
 
 func (rbt *RBTree[T]) newRBNode(data T) *RBNode[T] {
-	return &RBNode[T]{
-		Data:   data,
-		left:   rbt.Nil,
-		right:  rbt.Nil,
-		parent: rbt.Nil,
-		color:  RED,
+	if rbt.pool == nil {
+		return &RBNode[T]{
+			Data:   data,
+			left:   rbt.Nil,
+			right:  rbt.Nil,
+			parent: rbt.Nil,
+			color:  RED,
+			size:   1,
+		}
 	}
+
+	z := rbt.pool.Get()
+	z.Data, z.color, z.size = data, RED, 1
+	z.left, z.right, z.parent = rbt.Nil, rbt.Nil, rbt.Nil
+	return z
+}
+
+// handOut marks x as handed out in pooled mode, and returns x.
+func (rbt *RBTree[T]) handOut(x *RBNode[T]) *RBNode[T] {
+	if rbt.pool != nil && x != nil && x != rbt.Nil {
+		x.escaped = true
+	}
+	return x
 }
 
 func (rbt *RBTree[T]) Min() *RBNode[T] {
-	return rbt.min(rbt.Root)
+	return rbt.handOut(rbt.min(rbt.Root))
 }
 
 func (rbt *RBTree[T]) min(node *RBNode[T]) *RBNode[T] {
@@ -53,7 +96,7 @@ func (rbt *RBTree[T]) min(node *RBNode[T]) *RBNode[T] {
 }
 
 func (rbt *RBTree[T]) Max() *RBNode[T] {
-	return rbt.max(rbt.Root)
+	return rbt.handOut(rbt.max(rbt.Root))
 }
 
 func (rbt *RBTree[T]) max(node *RBNode[T]) *RBNode[T] {
@@ -68,6 +111,10 @@ func (rbt *RBTree[T]) max(node *RBNode[T]) *RBNode[T] {
 }
 
 func (rbt *RBTree[T]) Next(node *RBNode[T]) *RBNode[T] {
+	return rbt.handOut(rbt.next(node))
+}
+
+func (rbt *RBTree[T]) next(node *RBNode[T]) *RBNode[T] {
 	if node.right != rbt.Nil {
 		return rbt.min(node.right)
 	}
@@ -79,6 +126,10 @@ func (rbt *RBTree[T]) Next(node *RBNode[T]) *RBNode[T] {
 }
 
 func (rbt *RBTree[T]) Prev(node *RBNode[T]) *RBNode[T] {
+	return rbt.handOut(rbt.prev(node))
+}
+
+func (rbt *RBTree[T]) prev(node *RBNode[T]) *RBNode[T] {
 	if node.left != rbt.Nil {
 		return rbt.max(node.left)
 	}
@@ -89,6 +140,234 @@ func (rbt *RBTree[T]) Prev(node *RBNode[T]) *RBNode[T] {
 	return x.parent
 }
 
+// Find returns the node holding a value equal to v under rbt.Less,
+// or nil if there is no such node. If there are several of them,
+// the first one in order is returned.
+func (rbt *RBTree[T]) Find(v T) *RBNode[T] {
+	return rbt.handOut(rbt.find(v))
+}
+
+func (rbt *RBTree[T]) find(v T) *RBNode[T] {
+	if x := rbt.lowerBound(v); x != nil && !rbt.Less(v, x.Data) {
+		return x
+	}
+	return nil
+}
+
+// LowerBound returns the first node in order whose value is not less than v,
+// or nil if there is no such node.
+//
+// Since Insert places a value after all values equal to it,
+// the first node among the equal ones is the earliest inserted.
+func (rbt *RBTree[T]) LowerBound(v T) *RBNode[T] {
+	return rbt.handOut(rbt.lowerBound(v))
+}
+
+func (rbt *RBTree[T]) lowerBound(v T) *RBNode[T] {
+	var found *RBNode[T]
+	for x := rbt.Root; x != rbt.Nil; {
+		if rbt.Less(x.Data, v) {
+			x = x.right
+		} else {
+			found, x = x, x.left
+		}
+	}
+	return found
+}
+
+// UpperBound returns the first node in order whose value is greater than v,
+// or nil if there is no such node.
+func (rbt *RBTree[T]) UpperBound(v T) *RBNode[T] {
+	var found *RBNode[T]
+	for x := rbt.Root; x != rbt.Nil; {
+		if rbt.Less(v, x.Data) {
+			found, x = x, x.left
+		} else {
+			x = x.right
+		}
+	}
+	return rbt.handOut(found)
+}
+
+// Ceiling returns the node of the least value greater than or equal to v,
+// or nil if there is no such node. It is the same as LowerBound.
+func (rbt *RBTree[T]) Ceiling(v T) *RBNode[T] {
+	return rbt.LowerBound(v)
+}
+
+// Floor returns the node of the greatest value less than or equal to v,
+// or nil if there is no such node. If there are several of them,
+// the last one in order, which is the latest inserted, is returned.
+func (rbt *RBTree[T]) Floor(v T) *RBNode[T] {
+	var found *RBNode[T]
+	for x := rbt.Root; x != rbt.Nil; {
+		if rbt.Less(v, x.Data) {
+			x = x.left
+		} else {
+			found, x = x, x.right
+		}
+	}
+	return rbt.handOut(found)
+}
+
+// Contains returns whether rbt holds a value equal to v under rbt.Less.
+func (rbt *RBTree[T]) Contains(v T) bool {
+	return rbt.find(v) != nil
+}
+
+// DeleteValue deletes a node holding a value equal to v,
+// and returns false if there is no such node.
+// In pooled mode, the node is reused by later insertions
+// if it has not been handed out.
+func (rbt *RBTree[T]) DeleteValue(v T) bool {
+	z := rbt.find(v)
+	if z == nil {
+		return false
+	}
+	rbt.Delete(z)
+	if rbt.pool != nil && !z.escaped {
+		rbt.pool.Put(z)
+	}
+	return true
+}
+
+// All returns an iterator over the values of rbt in order.
+//
+// The iterators of rbt find the next node before yielding the current one,
+// so the node just yielded may be deleted during the iteration.
+// Any other modification makes the rest of the iteration unspecified.
+func (rbt *RBTree[T]) All() iter.Seq[T] {
+	return rbt.values(rbt.ascend(rbt.min(rbt.Root), func(T) bool { return true }))
+}
+
+// Backward returns an iterator over the values of rbt in reverse order.
+func (rbt *RBTree[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for x := rbt.max(rbt.Root); x != rbt.Nil; {
+			prev := rbt.prev(x)
+			if !yield(x.Data) {
+				return
+			}
+			x = prev
+		}
+	}
+}
+
+// Nodes returns an iterator over the nodes of rbt in order.
+func (rbt *RBTree[T]) Nodes() iter.Seq[*RBNode[T]] {
+	return func(yield func(*RBNode[T]) bool) {
+		for x := range rbt.ascend(rbt.min(rbt.Root), func(T) bool { return true }) {
+			if !yield(rbt.handOut(x)) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator over the values v of rbt with lo <= v < hi
+// in order.
+func (rbt *RBTree[T]) Range(lo, hi T) iter.Seq[T] {
+	return rbt.values(rbt.ascend(rbt.lowerBound(lo), func(v T) bool {
+		return rbt.Less(v, hi)
+	}))
+}
+
+// RangeClosed returns an iterator over the values v of rbt with
+// lo <= v <= hi in order.
+func (rbt *RBTree[T]) RangeClosed(lo, hi T) iter.Seq[T] {
+	return rbt.values(rbt.ascend(rbt.lowerBound(lo), func(v T) bool {
+		return !rbt.Less(hi, v)
+	}))
+}
+
+// ascend iterates over the nodes from x in order while in holds.
+// x may be nil or rbt.Nil for an empty iteration.
+func (rbt *RBTree[T]) ascend(x *RBNode[T], in func(T) bool) iter.Seq[*RBNode[T]] {
+	return func(yield func(*RBNode[T]) bool) {
+		for x := x; x != nil && x != rbt.Nil && in(x.Data); {
+			next := rbt.next(x)
+			if !yield(x) {
+				return
+			}
+			x = next
+		}
+	}
+}
+
+func (rbt *RBTree[T]) values(nodes iter.Seq[*RBNode[T]]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for x := range nodes {
+			if !yield(x.Data) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of nodes in rbt.
+func (rbt *RBTree[T]) Len() int {
+	return rbt.Root.size
+}
+
+// Select returns the node of the k-th smallest value, counting from 0,
+// or nil if k is out of range.
+//
+// Actual cost is O(lg n).
+func (rbt *RBTree[T]) Select(k int) *RBNode[T] {
+	if k < 0 || k >= rbt.Len() {
+		return nil
+	}
+
+	x := rbt.Root
+	for k != x.left.size {
+		if k < x.left.size {
+			x = x.left
+		} else {
+			k -= x.left.size + 1
+			x = x.right
+		}
+	}
+	return rbt.handOut(x)
+}
+
+// Rank returns the number of values in rbt that are less than v,
+// which is also the index of LowerBound(v) in order.
+//
+// Actual cost is O(lg n).
+func (rbt *RBTree[T]) Rank(v T) int {
+	r := 0
+	for x := rbt.Root; x != rbt.Nil; {
+		if rbt.Less(x.Data, v) {
+			r += x.left.size + 1
+			x = x.right
+		} else {
+			x = x.left
+		}
+	}
+	return r
+}
+
+// rankAfter returns the number of values in rbt that are not greater than v.
+func (rbt *RBTree[T]) rankAfter(v T) int {
+	r := 0
+	for x := rbt.Root; x != rbt.Nil; {
+		if rbt.Less(v, x.Data) {
+			x = x.left
+		} else {
+			r += x.left.size + 1
+			x = x.right
+		}
+	}
+	return r
+}
+
+// CountRange returns the number of values v in rbt with lo <= v < hi.
+//
+// Actual cost is O(lg n).
+func (rbt *RBTree[T]) CountRange(lo, hi T) int {
+	return max(rbt.Rank(hi)-rbt.Rank(lo), 0)
+}
+
 func (rbt *RBTree[T]) Inorder() {
 	rbt.inorder(rbt.Root)
 }
@@ -102,6 +381,48 @@ func (rbt *RBTree[T]) inorder(node *RBNode[T]) {
 	rbt.inorder(node.right)
 }
 
+// Clone returns a deep copy of rbt, which shares no nodes with rbt.
+// The data in the nodes are copied by assignment.
+func (rbt *RBTree[T]) Clone() *RBTree[T] {
+	return rbt.clone(nil)
+}
+
+// CloneWithNodes is like Clone, but also returns a mapping from the nodes
+// of rbt to the corresponding nodes of the copy.
+func (rbt *RBTree[T]) CloneWithNodes() (*RBTree[T], map[*RBNode[T]]*RBNode[T]) {
+	nodes := make(map[*RBNode[T]]*RBNode[T])
+	c := rbt.clone(nodes)
+	for _, y := range nodes {
+		c.handOut(y)
+	}
+	return c, nodes
+}
+
+func (rbt *RBTree[T]) clone(nodes map[*RBNode[T]]*RBNode[T]) *RBTree[T] {
+	c := &RBTree[T]{}
+	c.Init(rbt.Less)
+	c.SetPooled(rbt.pool != nil)
+	c.SetParallel(rbt.parallel)
+	c.Nil.Data, c.augment = rbt.Nil.Data, rbt.augment
+	c.Root = c.cloneSubtree(rbt, rbt.Root, c.Nil, nodes)
+	return c
+}
+
+// cloneSubtree copies the subtree of src rooted at x under the node p of rbt.
+func (rbt *RBTree[T]) cloneSubtree(src *RBTree[T], x, p *RBNode[T], nodes map[*RBNode[T]]*RBNode[T]) *RBNode[T] {
+	if x == src.Nil {
+		return rbt.Nil
+	}
+
+	y := &RBNode[T]{Data: x.Data, parent: p, color: x.color, size: x.size}
+	y.left = rbt.cloneSubtree(src, x.left, y, nodes)
+	y.right = rbt.cloneSubtree(src, x.right, y, nodes)
+	if nodes != nil {
+		nodes[x] = y
+	}
+	return y
+}
+
 func (rbt *RBTree[T]) rotateLeft(x *RBNode[T]) {
 	y := x.right
 	x.right = y.left
@@ -120,6 +441,8 @@ func (rbt *RBTree[T]) rotateLeft(x *RBNode[T]) {
 
 	y.parent = x.parent
 	x.parent = y
+	rbt.update(x)
+	rbt.update(y)
 }
 
 func (rbt *RBTree[T]) rotateRight(x *RBNode[T]) {
@@ -140,12 +463,29 @@ func (rbt *RBTree[T]) rotateRight(x *RBNode[T]) {
 
 	y.parent = x.parent
 	x.parent = y
+	rbt.update(x)
+	rbt.update(y)
 }
 
 func (rbt *RBTree[T]) Insert(data T) *RBNode[T] {
+	return rbt.handOut(rbt.insert(data))
+}
+
+// Push inserts data into rbt like Insert, but returns no handle,
+// so that its node may be reused in pooled mode once deleted by DeleteValue.
+func (rbt *RBTree[T]) Push(data T) {
+	rbt.insert(data)
+}
+
+func (rbt *RBTree[T]) insert(data T) *RBNode[T] {
 	z := rbt.newRBNode(data)
 
 	rbt.Root = rbt.insertTo(rbt.Nil, rbt.Root, z)
+	if rbt.augment != nil {
+		for p := z; p != rbt.Nil; p = p.parent {
+			rbt.update(p)
+		}
+	}
 	rbt.insertFixup(z)
 	rbt.Root.color = BLACK
 
@@ -158,6 +498,7 @@ func (rbt *RBTree[T]) insertTo(p, x, z *RBNode[T]) *RBNode[T] {
 		return z
 	}
 
+	x.size++
 	if rbt.Less(z.Data, x.Data) {
 		x.left = rbt.insertTo(x, x.left, z)
 	} else {
@@ -230,6 +571,9 @@ func (rbt *RBTree[T]) Delete(z *RBNode[T]) {
 		y.left.parent = y
 		y.color = z.color
 	}
+	for p := x.parent; p != rbt.Nil; p = p.parent {
+		rbt.update(p)
+	}
 
 	if yOriginalColor == BLACK {
 		rbt.deleteFixup(x)
@@ -291,6 +635,14 @@ func (rbt *RBTree[T]) deleteFixup(x *RBNode[T]) {
 	x.color = BLACK
 }
 
+// update recomputes the size and the augmented data of x from its children.
+func (rbt *RBTree[T]) update(x *RBNode[T]) {
+	x.size = x.left.size + x.right.size + 1
+	if rbt.augment != nil {
+		rbt.augment(x)
+	}
+}
+
 func (rbt *RBTree[T]) transplant(u, v *RBNode[T]) {
 	if u.parent == rbt.Nil {
 		rbt.Root = v
@@ -0,0 +1,79 @@
+package bst
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// bruteBound returns the first item in items for which ok holds if first,
+// otherwise the last one, and false if there is no such item.
+func bruteBound(items []item, first bool, ok func(x item) bool) (item, bool) {
+	found, has := item{}, false
+	for _, x := range items {
+		if ok(x) {
+			found, has = x, true
+			if first {
+				break
+			}
+		}
+	}
+	return found, has
+}
+
+func checkBound(t *testing.T, name string, k int, got *RBNode[item], want item, ok bool) {
+	t.Helper()
+	if (got != nil) != ok || ok && got.Data != want {
+		t.Fatalf("%s(%d): got: %v, expect: %v %v", name, k, got, want, ok)
+	}
+}
+
+func TestRBTree_Bounds(t *testing.T) {
+	rbt := newItemTree()
+	for i, k := range []int{2, 4, 4, 6, 4} {
+		rbt.Insert(item{k, i})
+	}
+
+	// equal values are placed in their insertion order
+	checkBound(t, "LowerBound", 4, rbt.LowerBound(item{key: 4}), item{4, 1}, true)
+	checkBound(t, "Ceiling", 4, rbt.Ceiling(item{key: 4}), item{4, 1}, true)
+	checkBound(t, "Floor", 4, rbt.Floor(item{key: 4}), item{4, 4}, true)
+	checkBound(t, "UpperBound", 4, rbt.UpperBound(item{key: 4}), item{6, 3}, true)
+
+	checkBound(t, "Floor", 1, rbt.Floor(item{key: 1}), item{}, false)
+	checkBound(t, "LowerBound", 7, rbt.LowerBound(item{key: 7}), item{}, false)
+	checkBound(t, "UpperBound", 6, rbt.UpperBound(item{key: 6}), item{}, false)
+	checkBound(t, "Ceiling", 3, rbt.Ceiling(item{key: 3}), item{4, 1}, true)
+	checkBound(t, "Floor", 5, rbt.Floor(item{key: 5}), item{4, 4}, true)
+}
+
+func TestRBTree_BoundsRandom(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	rbt := newItemTree()
+	var items []item
+	for i := 0; i < 3000; i++ {
+		k := rng.Intn(200)
+		if rng.Intn(3) > 0 {
+			rbt.Insert(item{k, i})
+			items = insertItem(items, item{k, i})
+		} else {
+			items, _ = deleteItem(items, k)
+			rbt.DeleteValue(item{key: k})
+		}
+
+		k = rng.Intn(220) - 10
+		v := item{key: k}
+		want, ok := bruteBound(items, true, func(x item) bool { return x.key >= k })
+		checkBound(t, "LowerBound", k, rbt.LowerBound(v), want, ok)
+		checkBound(t, "Ceiling", k, rbt.Ceiling(v), want, ok)
+
+		want, ok = bruteBound(items, true, func(x item) bool { return x.key > k })
+		checkBound(t, "UpperBound", k, rbt.UpperBound(v), want, ok)
+
+		want, ok = bruteBound(items, false, func(x item) bool { return x.key <= k })
+		checkBound(t, "Floor", k, rbt.Floor(v), want, ok)
+	}
+}
@@ -0,0 +1,80 @@
+package bst
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRBTree_Find(t *testing.T) {
+	rbt := newItemTree()
+	for i, k := range []int{5, 3, 5, 8, 5, 1} {
+		rbt.Insert(item{k, i})
+	}
+
+	// the earliest inserted one among the equal values is found
+	if x := rbt.Find(item{key: 5}); x == nil || x.Data != (item{5, 0}) {
+		t.Fatalf("got: %v, expect: %v", x, item{5, 0})
+	}
+	if x := rbt.Find(item{key: 4}); x != nil {
+		t.Fatalf("got: %v, expect: nil", x.Data)
+	}
+	if !rbt.Contains(item{key: 8}) || rbt.Contains(item{key: 9}) {
+		t.Fatal("Contains should report 8 but not 9")
+	}
+
+	if rbt.DeleteValue(item{key: 4}) {
+		t.Fatal("should not delete an absent value")
+	}
+	if !rbt.DeleteValue(item{key: 5}) {
+		t.Fatal("should delete 5")
+	}
+	if x := rbt.Find(item{key: 5}); x == nil || x.Data != (item{5, 2}) {
+		t.Fatalf("got: %v, expect: %v", x, item{5, 2})
+	}
+}
+
+func TestRBTree_FindRandom(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	rbt := newItemTree()
+	var items []item
+	for i := 0; i < 4000; i++ {
+		k := rng.Intn(300)
+		if rng.Intn(3) > 0 {
+			rbt.Insert(item{k, i})
+			items = insertItem(items, item{k, i})
+		} else {
+			var ok bool
+			items, ok = deleteItem(items, k)
+			if got := rbt.DeleteValue(item{key: k}); got != ok {
+				t.Fatalf("DeleteValue(%d): got: %v, expect: %v", k, got, ok)
+			}
+		}
+		if rbt.Len() != len(items) {
+			t.Fatalf("got: %d, expect: %d", rbt.Len(), len(items))
+		}
+
+		k = rng.Intn(300)
+		want, ok := item{}, false
+		if j := slices.IndexFunc(items, func(x item) bool { return x.key == k }); j >= 0 {
+			want, ok = items[j], true
+		}
+		if x := rbt.Find(item{key: k}); (x != nil) != ok || ok && x.Data != want {
+			t.Fatalf("Find(%d): got: %v, expect: %v", k, x, want)
+		}
+		if rbt.Contains(item{key: k}) != ok {
+			t.Fatalf("Contains(%d): got: %v, expect: %v", k, !ok, ok)
+		}
+
+		if i%100 == 0 {
+			checkRBTree(t, rbt)
+			if got := slices.Collect(rbt.All()); !slices.Equal(got, items) {
+				t.Fatalf("got: %v, expect: %v", got, items)
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package bst
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRBTree_IterRandom(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	rbt := newItemTree()
+	var items []item
+	for i := 0; i < 1000; i++ {
+		k := rng.Intn(100)
+		if rng.Intn(4) > 0 {
+			rbt.Insert(item{k, i})
+			items = insertItem(items, item{k, i})
+		} else {
+			items, _ = deleteItem(items, k)
+			rbt.DeleteValue(item{key: k})
+		}
+
+		if got := slices.Collect(rbt.All()); !slices.Equal(got, items) {
+			t.Fatalf("All: got: %v, expect: %v", got, items)
+		}
+		backward := slices.Clone(items)
+		slices.Reverse(backward)
+		if got := slices.Collect(rbt.Backward()); !slices.Equal(got, backward) {
+			t.Fatalf("Backward: got: %v, expect: %v", got, backward)
+		}
+		var nodes []item
+		for x := range rbt.Nodes() {
+			nodes = append(nodes, x.Data)
+		}
+		if !slices.Equal(nodes, items) {
+			t.Fatalf("Nodes: got: %v, expect: %v", nodes, items)
+		}
+
+		lo := rng.Intn(110) - 5
+		hi := lo + rng.Intn(30)
+		var half, closed []item
+		for _, x := range items {
+			if lo <= x.key && x.key < hi {
+				half = append(half, x)
+			}
+			if lo <= x.key && x.key <= hi {
+				closed = append(closed, x)
+			}
+		}
+		if got := slices.Collect(rbt.Range(item{key: lo}, item{key: hi})); !slices.Equal(got, half) {
+			t.Fatalf("Range(%d, %d): got: %v, expect: %v", lo, hi, got, half)
+		}
+		if got := slices.Collect(rbt.RangeClosed(item{key: lo}, item{key: hi})); !slices.Equal(got, closed) {
+			t.Fatalf("RangeClosed(%d, %d): got: %v, expect: %v", lo, hi, got, closed)
+		}
+	}
+}
+
+func TestRBTree_IterBreak(t *testing.T) {
+	rbt := newIntTree()
+	for i := 0; i < 100; i++ {
+		rbt.Insert(i)
+	}
+
+	for name, seq := range map[string]func(func(int) bool){
+		"All":      rbt.All(),
+		"Backward": rbt.Backward(),
+		"Range":    rbt.Range(10, 90),
+	} {
+		n := 0
+		for range seq {
+			if n++; n == 5 {
+				break
+			}
+		}
+		if n != 5 {
+			t.Fatalf("%s: got: %d, expect: %d", name, n, 5)
+		}
+	}
+
+	// an iterator can be used again
+	all := rbt.All()
+	for range 2 {
+		if n := len(slices.Collect(all)); n != 100 {
+			t.Fatalf("got: %d, expect: %d", n, 100)
+		}
+	}
+}
+
+func TestRBTree_DeleteDuringIter(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	rbt := newItemTree()
+	var items []item
+	for i := 0; i < 500; i++ {
+		x := item{rng.Intn(50), i}
+		rbt.Insert(x)
+		items = insertItem(items, x)
+	}
+
+	// delete some of the nodes just yielded, including equal values
+	var got, kept []item
+	for x := range rbt.Nodes() {
+		got = append(got, x.Data)
+		if rng.Intn(2) == 0 {
+			rbt.Delete(x)
+		} else {
+			kept = append(kept, x.Data)
+		}
+	}
+	if !slices.Equal(got, items) {
+		t.Fatalf("got: %v, expect: %v", got, items)
+	}
+	checkRBTree(t, rbt)
+	if got := slices.Collect(rbt.All()); !slices.Equal(got, kept) {
+		t.Fatalf("got: %v, expect: %v", got, kept)
+	}
+
+	// the same holds for the values of distinct keys
+	ints := newIntTree()
+	for i := 0; i < 200; i++ {
+		ints.Insert(i)
+	}
+	n := 0
+	for v := range ints.Backward() {
+		if v%3 != 0 {
+			ints.DeleteValue(v)
+		}
+		n++
+	}
+	if n != 200 {
+		t.Fatalf("got: %d, expect: %d", n, 200)
+	}
+	for v := range ints.All() {
+		if v%3 != 0 {
+			t.Fatalf("%d should be deleted", v)
+		}
+	}
+	checkRBTree(t, ints)
+}
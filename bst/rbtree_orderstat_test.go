@@ -0,0 +1,90 @@
+package bst
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestRBTree_Select(t *testing.T) {
+	rbt := newIntTree()
+	for _, v := range []int{5, 1, 9, 3, 7} {
+		rbt.Insert(v)
+	}
+
+	for k, want := range []int{1, 3, 5, 7, 9} {
+		if x := rbt.Select(k); x == nil || x.Data != want {
+			t.Fatalf("Select(%d): got: %v, expect: %d", k, x, want)
+		}
+		if r := rbt.Rank(want); r != k {
+			t.Fatalf("Rank(%d): got: %d, expect: %d", want, r, k)
+		}
+	}
+	if rbt.Select(-1) != nil || rbt.Select(5) != nil {
+		t.Fatal("Select should return nil out of range")
+	}
+	if n := rbt.CountRange(7, 3); n != 0 {
+		t.Fatalf("got: %d, expect: %d", n, 0)
+	}
+}
+
+func TestRBTree_OrderStatRandom(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	rbt := newItemTree()
+	var items []item
+	handles := map[item]*RBNode[item]{}
+	for i := 0; i < 3000; i++ {
+		k := rng.Intn(200)
+		switch op := rng.Intn(4); {
+		case op < 2 || len(items) == 0:
+			handles[item{k, i}] = rbt.Insert(item{k, i})
+			items = insertItem(items, item{k, i})
+		case op == 2:
+			// delete by handle, which may be any of the equal values
+			j := rng.Intn(len(items))
+			rbt.Delete(handles[items[j]])
+			delete(handles, items[j])
+			items = append(items[:j], items[j+1:]...)
+		default:
+			var ok bool
+			if items, ok = deleteItem(items, k); ok {
+				delete(handles, rbt.Find(item{key: k}).Data)
+			}
+			rbt.DeleteValue(item{key: k})
+		}
+		if rbt.Len() != len(items) {
+			t.Fatalf("got: %d, expect: %d", rbt.Len(), len(items))
+		}
+
+		if len(items) > 0 {
+			j := rng.Intn(len(items))
+			if x := rbt.Select(j); x == nil || x.Data != items[j] {
+				t.Fatalf("Select(%d): got: %v, expect: %v", j, x, items[j])
+			}
+		}
+
+		lo := rng.Intn(220) - 10
+		hi := lo + rng.Intn(40)
+		rank, count := 0, 0
+		for _, x := range items {
+			if x.key < lo {
+				rank++
+			} else if x.key < hi {
+				count++
+			}
+		}
+		if r := rbt.Rank(item{key: lo}); r != rank {
+			t.Fatalf("Rank(%d): got: %d, expect: %d", lo, r, rank)
+		}
+		if n := rbt.CountRange(item{key: lo}, item{key: hi}); n != count {
+			t.Fatalf("CountRange(%d, %d): got: %d, expect: %d", lo, hi, n, count)
+		}
+
+		if i%100 == 0 {
+			checkRBTree(t, rbt)
+		}
+	}
+}
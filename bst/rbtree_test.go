@@ -0,0 +1,195 @@
+package bst
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+func newIntTree() *RBTree[int] {
+	rbt := &RBTree[int]{}
+	rbt.Init(cmp.Less[int])
+	return rbt
+}
+
+// item is ordered by its key only,
+// so that the items with equal keys can be told apart by their ids.
+type item struct {
+	key, id int
+}
+
+func lessItem(a, b item) bool {
+	return a.key < b.key
+}
+
+func newItemTree() *RBTree[item] {
+	rbt := &RBTree[item]{}
+	rbt.Init(lessItem)
+	return rbt
+}
+
+// insertItem inserts x into the sorted items after the ones
+// with equal keys, as RBTree.Insert does.
+func insertItem(items []item, x item) []item {
+	j := sort.Search(len(items), func(i int) bool { return items[i].key > x.key })
+	return slices.Insert(items, j, x)
+}
+
+// deleteItem deletes the first item with the given key from the sorted
+// items, as RBTree.DeleteValue does, and returns whether there was one.
+func deleteItem(items []item, key int) ([]item, bool) {
+	j := sort.Search(len(items), func(i int) bool { return items[i].key >= key })
+	if j == len(items) || items[j].key != key {
+		return items, false
+	}
+	return slices.Delete(items, j, j+1), true
+}
+
+// checkRBTree checks the red-black properties, the parent links,
+// the sizes and the order of the nodes of rbt.
+func checkRBTree[T any](t *testing.T, rbt *RBTree[T]) {
+	t.Helper()
+
+	if rbt.Nil.color != BLACK || rbt.Nil.size != 0 {
+		t.Fatal("the sentinel should be black with size 0")
+	}
+	if rbt.Root.color != BLACK {
+		t.Fatal("the root should be black")
+	}
+	if rbt.Root != rbt.Nil && rbt.Root.parent != rbt.Nil {
+		t.Fatal("the root should have no parent")
+	}
+
+	var values []T
+	var walk func(x *RBNode[T]) int
+	walk = func(x *RBNode[T]) int {
+		if x == rbt.Nil {
+			return 0
+		}
+		for _, c := range []*RBNode[T]{x.left, x.right} {
+			if c != rbt.Nil && c.parent != x {
+				t.Fatalf("broken parent link under %v", x.Data)
+			}
+			if x.color == RED && c.color == RED {
+				t.Fatalf("red node %v has a red child", x.Data)
+			}
+		}
+
+		lh := walk(x.left)
+		values = append(values, x.Data)
+		rh := walk(x.right)
+		if lh != rh {
+			t.Fatalf("black heights %d and %d differ under %v", lh, rh, x.Data)
+		}
+		if x.size != x.left.size+x.right.size+1 {
+			t.Fatalf("got size %d, expect %d at %v", x.size, x.left.size+x.right.size+1, x.Data)
+		}
+		if x.color == BLACK {
+			lh++
+		}
+		return lh
+	}
+	walk(rbt.Root)
+
+	for i := 1; i < len(values); i++ {
+		if rbt.Less(values[i], values[i-1]) {
+			t.Fatalf("%v is placed before %v", values[i-1], values[i])
+		}
+	}
+}
+
+func TestRBTree_Pooled(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	rbt := newIntTree()
+	rbt.SetPooled(true)
+
+	var keys []int
+	handles := map[*RBNode[int]]int{}
+	for i := 0; i < 4096; i++ {
+		if len(keys) == 0 || rng.Intn(3) > 0 {
+			x := rng.Intn(1 << 12)
+			if rng.Intn(4) == 0 {
+				handles[rbt.Insert(x)] = x
+			} else {
+				rbt.Push(x)
+			}
+			j, _ := slices.BinarySearch(keys, x)
+			keys = slices.Insert(keys, j, x)
+			continue
+		}
+
+		x := keys[rng.Intn(len(keys))]
+		if rng.Intn(8) == 0 {
+			// a node found is handed out, and must not be reused
+			handles[rbt.Find(x)] = x
+		}
+		if !rbt.DeleteValue(x) {
+			t.Fatalf("%d should be in the tree", x)
+		}
+		j, _ := slices.BinarySearch(keys, x)
+		keys = slices.Delete(keys, j, j+1)
+	}
+
+	if got := slices.Collect(rbt.All()); !slices.Equal(got, keys) {
+		t.Fatalf("got: %v, expect: %v", got, keys)
+	}
+	// the handed out nodes are never recycled, so they keep their values
+	for h, x := range handles {
+		if h.Data != x {
+			t.Fatalf("got: %d, expect: %d", h.Data, x)
+		}
+	}
+}
+
+func TestRBTree_PooledReuse(t *testing.T) {
+	rbt := newIntTree()
+	rbt.SetPooled(true)
+
+	rbt.Push(1)
+	rbt.Insert(2)
+	rbt.DeleteValue(1)
+	if rbt.pool.Free() != 1 {
+		t.Fatalf("got: %d, expect: %d", rbt.pool.Free(), 1)
+	}
+	rbt.DeleteValue(2)
+	if rbt.pool.Free() != 1 {
+		t.Fatalf("got: %d, expect: %d", rbt.pool.Free(), 1)
+	}
+	rbt.Push(3)
+	if rbt.pool.Free() != 0 {
+		t.Fatalf("got: %d, expect: %d", rbt.pool.Free(), 0)
+	}
+}
+
+func BenchmarkRBTree_InsertDeleteValue(b *testing.B) {
+	b.ReportAllocs()
+	rbt := newIntTree()
+	for _, x := range rand.Perm(1 << 10) {
+		rbt.Insert(x)
+	}
+
+	for i := 0; i < b.N; i++ {
+		rbt.DeleteValue(i)
+		rbt.Insert(i + 1<<10)
+	}
+}
+
+func BenchmarkRBTree_PooledPushDeleteValue(b *testing.B) {
+	b.ReportAllocs()
+	rbt := newIntTree()
+	rbt.SetPooled(true)
+	for _, x := range rand.Perm(1 << 10) {
+		rbt.Push(x)
+	}
+
+	for i := 0; i < b.N; i++ {
+		rbt.DeleteValue(i)
+		rbt.Push(i + 1<<10)
+	}
+}
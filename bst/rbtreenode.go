@@ -12,4 +12,6 @@ type RBNode[T any] struct {
 	left, right *RBNode[T]
 	parent      *RBNode[T]
 	color       Color
+	size        int  // number of nodes in the subtree rooted at this node
+	escaped     bool // whether the node has been handed out in pooled mode
 }
@@ -0,0 +1,267 @@
+package bst
+
+import "sync"
+
+// parallelGrain is the least total size of two subtrees
+// that a set operation in parallel mode processes in separate goroutines.
+const parallelGrain = 1 << 12
+
+// SetParallel enables or disables the parallel mode of rbt,
+// in which Union, Intersection, Difference and SymmetricDifference
+// process large subtrees in separate goroutines.
+func (rbt *RBTree[T]) SetParallel(parallel bool) {
+	rbt.parallel = parallel
+}
+
+// The set operations below treat the trees as sets, so the values in each
+// tree must be distinct under Less, as in a TreeSet. They work on the nodes
+// in place: the nodes that remain keep their handles, and the values of t
+// that appear in the result are moved into rbt. t is left empty, unless t
+// is rbt itself, in which case Union and Intersection leave rbt unchanged,
+// and Difference and SymmetricDifference empty it.
+//
+// The algorithms split one tree by the root of the other and join the
+// results of the recursive calls, so each of them costs
+// O(m lg(n/m + 1)), where m and n are the sizes of the smaller and the
+// larger tree. In parallel mode, the recursive calls on large subtrees run
+// in parallel, so the augment function of the tree, if any, must be safe
+// for concurrent use.
+
+// Union moves the values of t that are not in rbt into rbt.
+// The values of t equal to those in rbt are dropped.
+func (rbt *RBTree[T]) Union(t *RBTree[T]) {
+	if t == rbt {
+		return
+	}
+	rbt.setOp(t, rbt.union)
+}
+
+// Intersection removes the values of rbt that are not in t.
+func (rbt *RBTree[T]) Intersection(t *RBTree[T]) {
+	if t == rbt {
+		return
+	}
+	rbt.setOp(t, rbt.intersection)
+}
+
+// Difference removes the values of rbt that are in t.
+func (rbt *RBTree[T]) Difference(t *RBTree[T]) {
+	if t == rbt {
+		rbt.Root = rbt.Nil
+		return
+	}
+	rbt.setOp(t, rbt.difference)
+}
+
+// SymmetricDifference removes the values of rbt that are in t,
+// and moves the values of t that are not in rbt into rbt.
+func (rbt *RBTree[T]) SymmetricDifference(t *RBTree[T]) {
+	if t == rbt {
+		rbt.Root = rbt.Nil
+		return
+	}
+	rbt.setOp(t, rbt.symmetricDifference)
+}
+
+// The functions below leave their arguments unchanged by working on copies
+// of both trees, so their actual cost is Θ(n + m) rather than the bound of
+// the methods above. Use the methods on trees that may be consumed.
+
+// Union returns a new tree holding the values in a or b,
+// leaving a and b unchanged.
+func Union[T any](a, b *RBTree[T]) *RBTree[T] {
+	c := a.Clone()
+	c.Union(b.Clone())
+	return c
+}
+
+// Intersection returns a new tree holding the values in both a and b,
+// leaving a and b unchanged.
+func Intersection[T any](a, b *RBTree[T]) *RBTree[T] {
+	c := a.Clone()
+	c.Intersection(b.Clone())
+	return c
+}
+
+// Difference returns a new tree holding the values in a but not in b,
+// leaving a and b unchanged.
+func Difference[T any](a, b *RBTree[T]) *RBTree[T] {
+	c := a.Clone()
+	c.Difference(b.Clone())
+	return c
+}
+
+// SymmetricDifference returns a new tree holding the values in exactly one
+// of a and b, leaving a and b unchanged.
+func SymmetricDifference[T any](a, b *RBTree[T]) *RBTree[T] {
+	c := a.Clone()
+	c.SymmetricDifference(b.Clone())
+	return c
+}
+
+// subtree is a detached subtree with its black height.
+type subtree[T any] struct {
+	x *RBNode[T]
+	h int
+}
+
+func (rbt *RBTree[T]) setOp(t *RBTree[T], op func(a, b subtree[T]) subtree[T]) {
+	rbt.shareNil(t)
+	res := op(subtree[T]{rbt.Root, rbt.blackHeight()}, subtree[T]{t.Root, t.blackHeight()})
+	rbt.Root, res.x.color = res.x, BLACK
+	t.Root = t.Nil
+}
+
+// children detaches the children of a.x, and returns them as subtrees.
+func (rbt *RBTree[T]) children(a subtree[T]) (subtree[T], subtree[T]) {
+	h := a.h
+	if a.x.color == BLACK {
+		h--
+	}
+	rbt.detach(a.x.left)
+	rbt.detach(a.x.right)
+	return subtree[T]{a.x.left, h}, subtree[T]{a.x.right, h}
+}
+
+// split3 splits a into the values less than key, the node equal to key
+// if any, and the values greater than key.
+func (rbt *RBTree[T]) split3(a subtree[T], key T) (subtree[T], *RBNode[T], subtree[T]) {
+	if a.x == rbt.Nil {
+		return a, nil, a
+	}
+
+	l, r := rbt.children(a)
+	switch {
+	case rbt.Less(a.x.Data, key):
+		rl, found, rr := rbt.split3(r, key)
+		return rbt.join(l, a.x, rl), found, rr
+	case rbt.Less(key, a.x.Data):
+		ll, found, lr := rbt.split3(l, key)
+		return ll, found, rbt.join(lr, a.x, r)
+	}
+	return l, a.x, r
+}
+
+// join joins l and r by the node k between them.
+func (rbt *RBTree[T]) join(l subtree[T], k *RBNode[T], r subtree[T]) subtree[T] {
+	x, h := rbt.join3(l.x, l.h, k, r.x, r.h)
+	return subtree[T]{x, h}
+}
+
+// join2 joins l and r, where the values of l are all less than those of r.
+func (rbt *RBTree[T]) join2(l, r subtree[T]) subtree[T] {
+	if l.x == rbt.Nil {
+		return r
+	}
+	rest, last := rbt.splitLast(l)
+	return rbt.join(rest, last, r)
+}
+
+// splitLast splits a into the node of its maximum and the others.
+func (rbt *RBTree[T]) splitLast(a subtree[T]) (subtree[T], *RBNode[T]) {
+	l, r := rbt.children(a)
+	if r.x == rbt.Nil {
+		return l, a.x
+	}
+	rest, last := rbt.splitLast(r)
+	return rbt.join(l, a.x, rest), last
+}
+
+// both runs f and g, in parallel if rbt is in parallel mode and n is large.
+func (rbt *RBTree[T]) both(n int, f, g func()) {
+	if !rbt.parallel || n < parallelGrain {
+		f()
+		g()
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		f()
+	}()
+	g()
+	wg.Wait()
+}
+
+func (rbt *RBTree[T]) union(a, b subtree[T]) subtree[T] {
+	if a.x == rbt.Nil {
+		return b
+	}
+	if b.x == rbt.Nil {
+		return a
+	}
+
+	n := a.x.size + b.x.size
+	al, ar := rbt.children(a)
+	bl, _, br := rbt.split3(b, a.x.Data)
+	var l, r subtree[T]
+	rbt.both(n, func() {
+		l = rbt.union(al, bl)
+	}, func() {
+		r = rbt.union(ar, br)
+	})
+	return rbt.join(l, a.x, r)
+}
+
+func (rbt *RBTree[T]) intersection(a, b subtree[T]) subtree[T] {
+	if a.x == rbt.Nil || b.x == rbt.Nil {
+		return subtree[T]{rbt.Nil, 0}
+	}
+
+	n := a.x.size + b.x.size
+	al, ar := rbt.children(a)
+	bl, found, br := rbt.split3(b, a.x.Data)
+	var l, r subtree[T]
+	rbt.both(n, func() {
+		l = rbt.intersection(al, bl)
+	}, func() {
+		r = rbt.intersection(ar, br)
+	})
+	if found != nil {
+		return rbt.join(l, a.x, r)
+	}
+	return rbt.join2(l, r)
+}
+
+func (rbt *RBTree[T]) difference(a, b subtree[T]) subtree[T] {
+	if a.x == rbt.Nil || b.x == rbt.Nil {
+		return a
+	}
+
+	// split a by the root of b, which is removed from a if found
+	n := a.x.size + b.x.size
+	bl, br := rbt.children(b)
+	al, _, ar := rbt.split3(a, b.x.Data)
+	var l, r subtree[T]
+	rbt.both(n, func() {
+		l = rbt.difference(al, bl)
+	}, func() {
+		r = rbt.difference(ar, br)
+	})
+	return rbt.join2(l, r)
+}
+
+func (rbt *RBTree[T]) symmetricDifference(a, b subtree[T]) subtree[T] {
+	if a.x == rbt.Nil {
+		return b
+	}
+	if b.x == rbt.Nil {
+		return a
+	}
+
+	n := a.x.size + b.x.size
+	al, ar := rbt.children(a)
+	bl, found, br := rbt.split3(b, a.x.Data)
+	var l, r subtree[T]
+	rbt.both(n, func() {
+		l = rbt.symmetricDifference(al, bl)
+	}, func() {
+		r = rbt.symmetricDifference(ar, br)
+	})
+	if found != nil {
+		return rbt.join2(l, r)
+	}
+	return rbt.join(l, a.x, r)
+}
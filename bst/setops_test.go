@@ -0,0 +1,172 @@
+package bst
+
+import (
+	"cmp"
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+// randomSet returns a tree of n distinct random values less than maxValue,
+// its sorted values and their handles.
+func randomSet(rng *rand.Rand, n, maxValue int) (*RBTree[int], []int, map[int]*RBNode[int]) {
+	rbt := newIntTree()
+	handles := map[int]*RBNode[int]{}
+	for _, v := range rng.Perm(maxValue)[:n] {
+		handles[v] = rbt.Insert(v)
+	}
+	return rbt, slices.Sorted(maps.Keys(handles)), handles
+}
+
+// bruteSetOp returns the sorted values v of a or b for which keep holds,
+// given whether v is in a and in b.
+func bruteSetOp(a, b []int, keep func(inA, inB bool) bool) []int {
+	var res []int
+	for _, v := range slices.Compact(slices.Sorted(slices.Values(append(slices.Clone(a), b...)))) {
+		_, inA := slices.BinarySearch(a, v)
+		_, inB := slices.BinarySearch(b, v)
+		if keep(inA, inB) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+var setOps = []struct {
+	name   string
+	method func(rbt, t *RBTree[int])
+	fn     func(a, b *RBTree[int]) *RBTree[int]
+	keep   func(inA, inB bool) bool
+}{
+	{"Union", (*RBTree[int]).Union, Union[int],
+		func(inA, inB bool) bool { return inA || inB }},
+	{"Intersection", (*RBTree[int]).Intersection, Intersection[int],
+		func(inA, inB bool) bool { return inA && inB }},
+	{"Difference", (*RBTree[int]).Difference, Difference[int],
+		func(inA, inB bool) bool { return inA && !inB }},
+	{"SymmetricDifference", (*RBTree[int]).SymmetricDifference, SymmetricDifference[int],
+		func(inA, inB bool) bool { return inA != inB }},
+}
+
+// testSetOps checks the set operations on random trees of sizes up to
+// maxSize, in parallel mode if parallel is set.
+func testSetOps(t *testing.T, rounds, maxSize int, parallel bool) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	for round := 0; round < rounds; round++ {
+		for _, op := range setOps {
+			maxValue := 2*maxSize + 1
+			a, av, ah := randomSet(rng, rng.Intn(maxSize+1), maxValue)
+			b, bv, bh := randomSet(rng, rng.Intn(maxSize+1), maxValue)
+			a.SetParallel(parallel)
+			want := bruteSetOp(av, bv, op.keep)
+
+			// the functional version leaves its arguments unchanged
+			c := op.fn(a, b)
+			checkRBTree(t, c)
+			if got := slices.Collect(c.All()); !slices.Equal(got, want) {
+				t.Fatalf("%s: got: %v, expect: %v", op.name, got, want)
+			}
+			checkRBTree(t, a)
+			checkRBTree(t, b)
+			if got := slices.Collect(a.All()); !slices.Equal(got, av) {
+				t.Fatalf("%s: a is changed to %v", op.name, got)
+			}
+			if got := slices.Collect(b.All()); !slices.Equal(got, bv) {
+				t.Fatalf("%s: b is changed to %v", op.name, got)
+			}
+
+			op.method(a, b)
+			checkRBTree(t, a)
+			if got := slices.Collect(a.All()); !slices.Equal(got, want) {
+				t.Fatalf("%s: got: %v, expect: %v", op.name, got, want)
+			}
+			if b.Len() != 0 {
+				t.Fatalf("%s: got: %d, expect: %d", op.name, b.Len(), 0)
+			}
+
+			// the nodes in the result are the ones of a, then those of b
+			for _, v := range want {
+				h, ok := ah[v]
+				if !ok {
+					h = bh[v]
+				}
+				root := h
+				for ; root.parent != a.Nil; root = root.parent {
+				}
+				if h.Data != v || root != a.Root {
+					t.Fatalf("%s: the handle of %d is not in the result", op.name, v)
+				}
+			}
+		}
+	}
+}
+
+func TestRBTree_SetOps(t *testing.T) {
+	testSetOps(t, 100, 300, false)
+}
+
+func TestRBTree_SetOpsParallel(t *testing.T) {
+	if testing.Short() {
+		t.Skip("large trees")
+	}
+	testSetOps(t, 2, 4*parallelGrain, true)
+}
+
+func TestRBTree_SetOpsSelf(t *testing.T) {
+	for _, op := range setOps {
+		rbt := newIntTree()
+		for i := 0; i < 100; i++ {
+			rbt.Insert(i)
+		}
+		var want []int
+		if op.keep(true, true) {
+			want = slices.Collect(rbt.All())
+		}
+
+		op.method(rbt, rbt)
+		checkRBTree(t, rbt)
+		if got := slices.Collect(rbt.All()); !slices.Equal(got, want) {
+			t.Fatalf("%s: got: %v, expect: %v", op.name, got, want)
+		}
+	}
+}
+
+func TestRBTree_SetOpsAugmented(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	type sumTree = RBTree[AugData[int, int]]
+	sum := func(v, left, right int) int { return left + v + right }
+	for name, method := range map[string]func(rbt, t *sumTree){
+		"Union":               (*sumTree).Union,
+		"Intersection":        (*sumTree).Intersection,
+		"Difference":          (*sumTree).Difference,
+		"SymmetricDifference": (*sumTree).SymmetricDifference,
+	} {
+		var a, b AugTree[int, int]
+		a.Init(cmp.Less[int], sum, 0)
+		b.Init(cmp.Less[int], sum, 0)
+		for _, v := range rng.Perm(2000)[:1000] {
+			a.Insert(v)
+		}
+		for _, v := range rng.Perm(2000)[:1000] {
+			b.Insert(v)
+		}
+
+		method(&a.tree, &b.tree)
+		checkAgg(t, &a)
+		total := 0
+		for v := range a.All() {
+			total += v
+		}
+		if a.Aggregate() != total {
+			t.Fatalf("%s: got: %d, expect: %d", name, a.Aggregate(), total)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package bst
+
+import (
+	"cmp"
+	"iter"
+)
+
+// TreeMap is a map sorted by its keys, which is backed by a red-black tree.
+// Unlike RBTree, a key is held at most once.
+type TreeMap[K, V any] struct {
+	tree RBTree[mapEntry[K, V]]
+}
+
+type mapEntry[K, V any] struct {
+	key   K
+	value V
+}
+
+// NewTreeMap returns an empty TreeMap ordered by the < operator of K.
+func NewTreeMap[K cmp.Ordered, V any]() *TreeMap[K, V] {
+	return NewTreeMapFunc[K, V](cmp.Less[K])
+}
+
+// NewTreeMapFunc returns an empty TreeMap ordered by less.
+// Two keys are the same key if neither of them is less than the other.
+func NewTreeMapFunc[K, V any](less func(a, b K) bool) *TreeMap[K, V] {
+	m := &TreeMap[K, V]{}
+	m.tree.Init(func(a, b mapEntry[K, V]) bool {
+		return less(a.key, b.key)
+	})
+	return m
+}
+
+func (m *TreeMap[K, V]) find(key K) *RBNode[mapEntry[K, V]] {
+	return m.tree.find(mapEntry[K, V]{key: key})
+}
+
+// Put sets the value of key to value, and returns whether key was
+// already in m.
+//
+// Actual cost is O(lg n).
+func (m *TreeMap[K, V]) Put(key K, value V) bool {
+	if node := m.find(key); node != nil {
+		node.Data.value = value
+		return true
+	}
+
+	m.tree.Push(mapEntry[K, V]{key, value})
+	return false
+}
+
+// Get returns the value of key, and whether key is in m.
+//
+// Actual cost is O(lg n).
+func (m *TreeMap[K, V]) Get(key K) (V, bool) {
+	if node := m.find(key); node != nil {
+		return node.Data.value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Delete removes key from m, and returns whether key was in m.
+//
+// Actual cost is O(lg n).
+func (m *TreeMap[K, V]) Delete(key K) bool {
+	return m.tree.DeleteValue(mapEntry[K, V]{key: key})
+}
+
+// Len returns the number of keys in m.
+func (m *TreeMap[K, V]) Len() int {
+	return m.tree.Len()
+}
+
+// Min returns the least key and its value, or false if m is empty.
+func (m *TreeMap[K, V]) Min() (K, V, bool) {
+	return m.entry(m.tree.min(m.tree.Root))
+}
+
+// Max returns the greatest key and its value, or false if m is empty.
+func (m *TreeMap[K, V]) Max() (K, V, bool) {
+	return m.entry(m.tree.max(m.tree.Root))
+}
+
+func (m *TreeMap[K, V]) entry(node *RBNode[mapEntry[K, V]]) (K, V, bool) {
+	if node == m.tree.Nil {
+		var e mapEntry[K, V]
+		return e.key, e.value, false
+	}
+	return node.Data.key, node.Data.value, true
+}
+
+// All returns an iterator over the keys and values of m in the order
+// of the keys. The key just yielded may be deleted during the iteration,
+// and the values may be updated by Put. Any other modification makes
+// the rest of the iteration unspecified.
+func (m *TreeMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := range m.tree.All() {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the keys of m in order.
+func (m *TreeMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of m in the order of the keys.
+func (m *TreeMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package bst
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTreeMap_Put(t *testing.T) {
+	m := NewTreeMap[string, int]()
+	if m.Put("b", 1) || m.Put("a", 2) {
+		t.Fatal("Put should report new keys")
+	}
+	if !m.Put("b", 3) {
+		t.Fatal("Put should report an existing key")
+	}
+	if v, ok := m.Get("b"); !ok || v != 3 {
+		t.Fatalf("got: %d, expect: %d", v, 3)
+	}
+	if _, ok := m.Get("c"); ok {
+		t.Fatal("c should not be in m")
+	}
+	if m.Len() != 2 {
+		t.Fatalf("got: %d, expect: %d", m.Len(), 2)
+	}
+}
+
+func TestTreeMap_Random(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	m := NewTreeMap[int, int]()
+	want := map[int]int{}
+	for i := 0; i < 3000; i++ {
+		k := rng.Intn(500)
+		if rng.Intn(3) > 0 {
+			_, ok := want[k]
+			if got := m.Put(k, i); got != ok {
+				t.Fatalf("Put(%d): got: %v, expect: %v", k, got, ok)
+			}
+			want[k] = i
+		} else {
+			_, ok := want[k]
+			if got := m.Delete(k); got != ok {
+				t.Fatalf("Delete(%d): got: %v, expect: %v", k, got, ok)
+			}
+			delete(want, k)
+		}
+		if m.Len() != len(want) {
+			t.Fatalf("got: %d, expect: %d", m.Len(), len(want))
+		}
+
+		k = rng.Intn(500)
+		v, ok := m.Get(k)
+		if wv, wok := want[k]; ok != wok || v != wv {
+			t.Fatalf("Get(%d): got: %d %v, expect: %d %v", k, v, ok, wv, wok)
+		}
+	}
+
+	keys := slices.Sorted(maps.Keys(want))
+	if got := slices.Collect(m.Keys()); !slices.Equal(got, keys) {
+		t.Fatalf("got: %v, expect: %v", got, keys)
+	}
+	var values []int
+	for _, k := range keys {
+		values = append(values, want[k])
+	}
+	if got := slices.Collect(m.Values()); !slices.Equal(got, values) {
+		t.Fatalf("got: %v, expect: %v", got, values)
+	}
+
+	if len(keys) > 0 {
+		if k, v, ok := m.Min(); !ok || k != keys[0] || v != want[k] {
+			t.Fatalf("got: %d %d, expect: %d %d", k, v, keys[0], want[keys[0]])
+		}
+		last := keys[len(keys)-1]
+		if k, v, ok := m.Max(); !ok || k != last || v != want[k] {
+			t.Fatalf("got: %d %d, expect: %d %d", k, v, last, want[last])
+		}
+	}
+}
+
+func TestTreeMap_ModifyDuringAll(t *testing.T) {
+	m := NewTreeMap[int, int]()
+	for k := 0; k < 100; k++ {
+		m.Put(k, k)
+	}
+
+	// delete the odd keys just yielded, and update the even ones
+	var keys []int
+	for k := range m.Keys() {
+		keys = append(keys, k)
+		if k%2 == 1 {
+			m.Delete(k)
+		} else {
+			m.Put(k, -k)
+		}
+	}
+	if len(keys) != 100 {
+		t.Fatalf("got %d keys, expect %d", len(keys), 100)
+	}
+
+	if m.Len() != 50 {
+		t.Fatalf("got: %d, expect: %d", m.Len(), 50)
+	}
+	for k, v := range m.All() {
+		if k%2 == 1 || v != -k {
+			t.Fatalf("got: %d %d, expect: %d %d", k, v, k, -k)
+		}
+	}
+	checkRBTree(t, &m.tree)
+}
+
+func TestTreeMap_Empty(t *testing.T) {
+	m := NewTreeMap[int, string]()
+	if _, _, ok := m.Min(); ok {
+		t.Fatal("Min of an empty map should report false")
+	}
+	if _, _, ok := m.Max(); ok {
+		t.Fatal("Max of an empty map should report false")
+	}
+	if m.Delete(1) {
+		t.Fatal("should not delete from an empty map")
+	}
+}
@@ -0,0 +1,126 @@
+package bst
+
+import (
+	"cmp"
+	"iter"
+)
+
+// TreeSet is a sorted set backed by a red-black tree,
+// which holds each value at most once.
+// Two values are the same if neither of them is less than the other.
+type TreeSet[T any] struct {
+	tree RBTree[T]
+}
+
+// NewTreeSet returns an empty TreeSet ordered by the < operator of T.
+func NewTreeSet[T cmp.Ordered]() *TreeSet[T] {
+	return NewTreeSetFunc(cmp.Less[T])
+}
+
+// NewTreeSetFunc returns an empty TreeSet ordered by less.
+func NewTreeSetFunc[T any](less func(a, b T) bool) *TreeSet[T] {
+	s := &TreeSet[T]{}
+	s.tree.Init(less)
+	return s
+}
+
+// Insert v into s and return its node. If v is already in s, the existing
+// node is returned with true, and s is not modified.
+//
+// Actual cost is O(lg n).
+func (s *TreeSet[T]) Insert(v T) (*RBNode[T], bool) {
+	if node := s.tree.Find(v); node != nil {
+		return node, true
+	}
+	return s.tree.Insert(v), false
+}
+
+// Find returns the node of v, or nil if v is not in s.
+func (s *TreeSet[T]) Find(v T) *RBNode[T] {
+	return s.tree.Find(v)
+}
+
+// Contains returns whether v is in s.
+func (s *TreeSet[T]) Contains(v T) bool {
+	return s.tree.Contains(v)
+}
+
+// Delete removes v from s, and returns whether v was in s.
+func (s *TreeSet[T]) Delete(v T) bool {
+	return s.tree.DeleteValue(v)
+}
+
+// Len returns the number of values in s.
+func (s *TreeSet[T]) Len() int {
+	return s.tree.Len()
+}
+
+// All returns an iterator over the values of s in order.
+func (s *TreeSet[T]) All() iter.Seq[T] {
+	return s.tree.All()
+}
+
+// TreeMultiset is a sorted multiset backed by a red-black tree,
+// which may hold equal values several times.
+// Equal values are kept in their insertion order.
+type TreeMultiset[T any] struct {
+	tree RBTree[T]
+}
+
+// NewTreeMultiset returns an empty TreeMultiset ordered by the < operator of T.
+func NewTreeMultiset[T cmp.Ordered]() *TreeMultiset[T] {
+	return NewTreeMultisetFunc(cmp.Less[T])
+}
+
+// NewTreeMultisetFunc returns an empty TreeMultiset ordered by less.
+func NewTreeMultisetFunc[T any](less func(a, b T) bool) *TreeMultiset[T] {
+	s := &TreeMultiset[T]{}
+	s.tree.Init(less)
+	return s
+}
+
+// Insert v into s and return its node, after all values equal to v.
+//
+// Actual cost is O(lg n).
+func (s *TreeMultiset[T]) Insert(v T) *RBNode[T] {
+	return s.tree.Insert(v)
+}
+
+// Contains returns whether v is in s.
+func (s *TreeMultiset[T]) Contains(v T) bool {
+	return s.tree.Contains(v)
+}
+
+// Count returns the number of values in s equal to v.
+//
+// Actual cost is O(lg n).
+func (s *TreeMultiset[T]) Count(v T) int {
+	return s.tree.rankAfter(v) - s.tree.Rank(v)
+}
+
+// Delete removes the earliest inserted value equal to v from s,
+// and returns whether there was such a value.
+func (s *TreeMultiset[T]) Delete(v T) bool {
+	return s.tree.DeleteValue(v)
+}
+
+// DeleteAll removes all values equal to v from s, and returns their number.
+//
+// Actual cost is O((k+1) lg n), where k is the number of the removed values.
+func (s *TreeMultiset[T]) DeleteAll(v T) int {
+	k := 0
+	for s.tree.DeleteValue(v) {
+		k++
+	}
+	return k
+}
+
+// Len returns the number of values in s, counting the equal ones separately.
+func (s *TreeMultiset[T]) Len() int {
+	return s.tree.Len()
+}
+
+// All returns an iterator over the values of s in order.
+func (s *TreeMultiset[T]) All() iter.Seq[T] {
+	return s.tree.All()
+}
@@ -0,0 +1,126 @@
+package bst
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRBTree_Equal(t *testing.T) {
+	rbt := newItemTree()
+	a, b, c := RBNode[item]{Data: item{1, 0}}, RBNode[item]{Data: item{1, 1}}, RBNode[item]{Data: item{2, 2}}
+	if !rbt.Equal(a, b) || !rbt.Equal(b, a) {
+		t.Fatal("values with equal keys should be equal")
+	}
+	// Equal used to ignore that b may be less than a
+	if rbt.Equal(c, a) || rbt.Equal(a, c) {
+		t.Fatal("values with different keys should not be equal")
+	}
+}
+
+func TestTreeSet_Insert(t *testing.T) {
+	s := NewTreeSetFunc(lessItem)
+	x, found := s.Insert(item{1, 0})
+	if found {
+		t.Fatal("1 should be new")
+	}
+	y, found := s.Insert(item{1, 1})
+	if !found || y != x || y.Data != (item{1, 0}) {
+		t.Fatalf("got: %v %v, expect: %v true", y.Data, found, item{1, 0})
+	}
+	if s.Len() != 1 {
+		t.Fatalf("got: %d, expect: %d", s.Len(), 1)
+	}
+}
+
+func TestTreeSet_Random(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	s := NewTreeSet[int]()
+	want := map[int]bool{}
+	for i := 0; i < 3000; i++ {
+		v := rng.Intn(500)
+		if rng.Intn(3) > 0 {
+			if _, found := s.Insert(v); found != want[v] {
+				t.Fatalf("Insert(%d): got: %v, expect: %v", v, found, want[v])
+			}
+			want[v] = true
+		} else {
+			if got := s.Delete(v); got != want[v] {
+				t.Fatalf("Delete(%d): got: %v, expect: %v", v, got, want[v])
+			}
+			delete(want, v)
+		}
+		if s.Len() != len(want) {
+			t.Fatalf("got: %d, expect: %d", s.Len(), len(want))
+		}
+
+		v = rng.Intn(500)
+		if s.Contains(v) != want[v] || (s.Find(v) != nil) != want[v] {
+			t.Fatalf("%d: got: %v, expect: %v", v, !want[v], want[v])
+		}
+	}
+
+	if got, values := slices.Collect(s.All()), slices.Sorted(maps.Keys(want)); !slices.Equal(got, values) {
+		t.Fatalf("got: %v, expect: %v", got, values)
+	}
+	checkRBTree(t, &s.tree)
+}
+
+func TestTreeMultiset_Random(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	s := NewTreeMultisetFunc(lessItem)
+	var items []item
+	for i := 0; i < 3000; i++ {
+		k := rng.Intn(100)
+		switch op := rng.Intn(8); {
+		case op < 5:
+			s.Insert(item{k, i})
+			items = insertItem(items, item{k, i})
+		case op < 7:
+			var ok bool
+			items, ok = deleteItem(items, k)
+			if got := s.Delete(item{key: k}); got != ok {
+				t.Fatalf("Delete(%d): got: %v, expect: %v", k, got, ok)
+			}
+		default:
+			n := len(items)
+			items = slices.DeleteFunc(items, func(x item) bool { return x.key == k })
+			if got := s.DeleteAll(item{key: k}); got != n-len(items) {
+				t.Fatalf("DeleteAll(%d): got: %d, expect: %d", k, got, n-len(items))
+			}
+		}
+		if s.Len() != len(items) {
+			t.Fatalf("got: %d, expect: %d", s.Len(), len(items))
+		}
+
+		k = rng.Intn(100)
+		count := 0
+		for _, x := range items {
+			if x.key == k {
+				count++
+			}
+		}
+		if got := s.Count(item{key: k}); got != count {
+			t.Fatalf("Count(%d): got: %d, expect: %d", k, got, count)
+		}
+		if s.Contains(item{key: k}) != (count > 0) {
+			t.Fatalf("Contains(%d): got: %v, expect: %v", k, count == 0, count > 0)
+		}
+
+		if i%100 == 0 {
+			// equal values are kept in their insertion order
+			if got := slices.Collect(s.All()); !slices.Equal(got, items) {
+				t.Fatalf("got: %v, expect: %v", got, items)
+			}
+		}
+	}
+	checkRBTree(t, &s.tree)
+}
@@ -0,0 +1,43 @@
+// Package pool provides the node pool shared by the pooled modes
+// of the data structures in this module.
+package pool
+
+// blockSize is the number of nodes allocated at once by a Pool.
+const blockSize = 64
+
+// Pool allocates nodes in blocks and recycles the released ones,
+// so that the data structures in pooled mode make far fewer allocations.
+// The zero value is an empty pool ready to use.
+type Pool[T any] struct {
+	block []T
+	free  []*T
+}
+
+// Get returns a zeroed node, which is a released one if there is any.
+func (p *Pool[T]) Get() *T {
+	if n := len(p.free); n > 0 {
+		x := p.free[n-1]
+		p.free = p.free[:n-1]
+		return x
+	}
+
+	if len(p.block) == 0 {
+		p.block = make([]T, blockSize)
+	}
+	x := &p.block[0]
+	p.block = p.block[1:]
+	return x
+}
+
+// Put clears x and keeps it for later use.
+// x must not be reachable from any handle held by the users.
+func (p *Pool[T]) Put(x *T) {
+	var zero T
+	*x = zero
+	p.free = append(p.free, x)
+}
+
+// Free returns the number of released nodes kept for later use.
+func (p *Pool[T]) Free() int {
+	return len(p.free)
+}
@@ -2,7 +2,10 @@ package priorityqueue
 
 type bHeapNode struct {
 	data              int
+	seq               uint64
 	degree            int
+	removed           bool
+	escaped           bool // handed out as a handle, so it is never recycled
 	child, prev, next *bHeapNode
 }
 
@@ -10,20 +13,28 @@ func (n *bHeapNode) Data() int {
 	return n.data
 }
 
-func (n *bHeapNode) Next() BiDirTreeNode {
+func (n *bHeapNode) valid() bool {
+	return n != nil && !n.removed
+}
+
+func (n *bHeapNode) Seq() uint64 {
+	return n.seq
+}
+
+func (n *bHeapNode) Next() *bHeapNode {
 	return n.next
 }
 
-func (n *bHeapNode) Prev() BiDirTreeNode {
+func (n *bHeapNode) Prev() *bHeapNode {
 	return n.prev
 }
 
-func (n *bHeapNode) SetNext(other BiDirTreeNode) {
-	n.next = other.(*bHeapNode)
+func (n *bHeapNode) SetNext(other *bHeapNode) {
+	n.next = other
 }
 
-func (n *bHeapNode) SetPrev(other BiDirTreeNode) {
-	n.prev = other.(*bHeapNode)
+func (n *bHeapNode) SetPrev(other *bHeapNode) {
+	n.prev = other
 }
 
 func (n *bHeapNode) AddSibling(s *bHeapNode) {
@@ -33,21 +44,17 @@ func (n *bHeapNode) AddSibling(s *bHeapNode) {
 	n.prev = s
 }
 
-func (n *bHeapNode) AddChild(ch BiDirTreeNode) {
-	if isNilPtr(ch) {
+func (n *bHeapNode) AddChild(ch *bHeapNode) {
+	if ch == nil {
 		return
 	}
 
-	if ch, ok := ch.(*bHeapNode); ok {
-		if n.child == nil {
-			n.child, ch.next, ch.prev = ch, ch, ch
-			n.degree = 1
-			return
-		}
-
-		n.child.AddSibling(ch)
-		n.degree++
-	} else {
-		panic("the type of ch is incorrect")
+	if n.child == nil {
+		n.child, ch.next, ch.prev = ch, ch, ch
+		n.degree = 1
+		return
 	}
+
+	n.child.AddSibling(ch)
+	n.degree++
 }
@@ -2,14 +2,50 @@ package priorityqueue
 
 import (
 	"fmt"
+	"iter"
 	"math"
+
+	"github.com/25349023/datastruct/internal/pool"
 )
 
 // BinomialHeap implementation that is introduced in
 // 'Fundamentals of Data Structures in C'
 type BinomialHeap struct {
-	min *bHeapNode
-	n   int
+	min    *bHeapNode
+	n      int
+	stable bool
+	pool   *pool.Pool[bHeapNode]
+	trees  []*bHeapNode // buffer of the consolidation
+}
+
+// SetStable enables or disables the stable mode of b, error if b is not empty.
+//
+// In stable mode, elements with equal keys leave the heap in the order they
+// were inserted, even if they come from different heaps melded together.
+func (b *BinomialHeap) SetStable(stable bool) error {
+	if !b.Empty() {
+		return fmt.Errorf("cannot change the mode of non-empty heap")
+	}
+	b.stable = stable
+	return nil
+}
+
+func (b *BinomialHeap) less(x, y *bHeapNode) bool {
+	return lessNode(x, y, b.stable)
+}
+
+// SetPooled enables or disables the pooled mode of b.
+//
+// In pooled mode, nodes are allocated in blocks, and the nodes inserted by
+// Push are recycled once they are popped. Nodes that have ever been handed
+// out as handles are never recycled, so a stale handle cannot refer to
+// another element.
+func (b *BinomialHeap) SetPooled(pooled bool) {
+	if !pooled {
+		b.pool = nil
+	} else if b.pool == nil {
+		b.pool = &pool.Pool[bHeapNode]{}
+	}
 }
 
 // Min peeks and returns the minimum of the heap.
@@ -20,6 +56,15 @@ func (b *BinomialHeap) Min() (int, error) {
 	return b.min.data, nil
 }
 
+// MinNode peeks and returns the node of the minimum of the heap.
+func (b *BinomialHeap) MinNode() (DataNode, error) {
+	if b.Empty() {
+		return nil, fmt.Errorf("heap is empty")
+	}
+	b.min.escaped = true
+	return b.min, nil
+}
+
 // Empty returns whether the heap is empty or not.
 func (b *BinomialHeap) Empty() bool {
 	return b.min == nil
@@ -29,22 +74,219 @@ func (b *BinomialHeap) Empty() bool {
 //
 // Amortized cost is O(1).
 func (b *BinomialHeap) Insert(x int) DataNode {
-	node := &bHeapNode{data: x}
+	node := b.newNode(x)
+	node.escaped = true
+	b.insertNode(node)
+	return node
+}
 
+// Push inserts x into the BinomialHeap like Insert, but returns no handle,
+// so that the node can be recycled after it is popped in pooled mode.
+//
+// Amortized cost is O(1).
+func (b *BinomialHeap) Push(x int) {
+	b.insertNode(b.newNode(x))
+}
+
+func (b *BinomialHeap) newNode(x int) *bHeapNode {
+	var node *bHeapNode
+	if b.pool != nil {
+		node = b.pool.Get()
+		node.data = x
+	} else {
+		node = &bHeapNode{data: x}
+	}
+
+	if b.stable {
+		node.seq = insertSeq.Add(1)
+	}
+	return node
+}
+
+// insertNode adds node into the root list as a single-node tree.
+func (b *BinomialHeap) insertNode(node *bHeapNode) {
 	defer func() { b.n++ }()
 
 	if b.min == nil {
 		b.min, node.prev, node.next = node, node, node
-		return node
+		return
 	}
 
 	b.min.AddSibling(node)
 
-	if x < b.min.data {
+	if b.less(node, b.min) {
 		b.min = node
 	}
+}
 
-	return node
+// release recycles the popped node x if it has never been handed out.
+func (b *BinomialHeap) release(x *bHeapNode) {
+	if b.pool != nil && !x.escaped {
+		b.pool.Put(x)
+	}
+}
+
+// InsertMany inserts all elements of xs into the BinomialHeap
+// and returns the inserted nodes in the order of xs.
+// The new nodes are allocated in one block
+// and spliced into the root list at once.
+//
+// Amortized cost is O(k), where k is len(xs).
+func (b *BinomialHeap) InsertMany(xs []int) []DataNode {
+	nodes := make([]DataNode, len(xs))
+	block := make([]bHeapNode, len(xs))
+	var list, min *bHeapNode
+	for i, x := range xs {
+		node := &block[i]
+		node.data, node.escaped = x, true
+		if b.stable {
+			node.seq = insertSeq.Add(1)
+		}
+		nodes[i] = node
+
+		if list == nil {
+			list, min, node.prev, node.next = node, node, node, node
+			continue
+		}
+		list.AddSibling(node)
+		if b.less(node, min) {
+			min = node
+		}
+	}
+
+	if list == nil {
+		return nodes
+	}
+	if b.min == nil {
+		b.min = min
+	} else {
+		mergeLists(b.min, list)
+		if b.less(min, b.min) {
+			b.min = min
+		}
+	}
+
+	b.n += len(xs)
+	return nodes
+}
+
+// DeleteMinN pops the k smallest elements from the BinomialHeap
+// and returns them in ascending order.
+// All elements are returned if the heap holds fewer than k elements.
+// It is equivalent to k calls of DeleteMin, which are already the
+// cheapest way to pop from a BinomialHeap, but allocates the result once.
+//
+// Actual cost is O(k lg n).
+func (b *BinomialHeap) DeleteMinN(k int) []int {
+	if b.Empty() || k <= 0 {
+		return nil
+	}
+
+	res := make([]int, 0, min(k, b.n))
+	b.extract(k, math.MaxInt, func(x int) bool {
+		res = append(res, x)
+		return true
+	})
+	return res
+}
+
+// ExtractUpTo pops all elements whose keys are at most limit from the
+// BinomialHeap and returns them in ascending order.
+//
+// Amortized cost is O(k lg n), where k is the number of popped elements.
+func (b *BinomialHeap) ExtractUpTo(limit int) []int {
+	var res []int
+	b.extract(b.n, limit, func(x int) bool {
+		res = append(res, x)
+		return true
+	})
+	return res
+}
+
+// ExtractUpToSeq returns an iterator that pops the elements whose keys are
+// at most limit from the BinomialHeap in ascending order. Elements that are not
+// reached because the iteration stops early stay in the heap.
+// The heap must not be modified until the iteration ends.
+func (b *BinomialHeap) ExtractUpToSeq(limit int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		b.extract(b.n, limit, yield)
+	}
+}
+
+// extract pops at most k elements whose keys are at most limit in ascending
+// order, and passes them to yield until it returns false.
+//
+// Unlike FibonacciHeap, the trees of a BinomialHeap are kept consolidated,
+// so there are only O(lg n) roots, and each DeleteMin already costs O(lg n)
+// in the worst case. Taking the nodes from the top of the trees by a separate
+// binary heap, and consolidating the remaining trees once afterwards, turned
+// out to be about twice as slow as repeated DeleteMin, so extract simply
+// repeats DeleteMin.
+func (b *BinomialHeap) extract(k, limit int, yield func(int) bool) {
+	for ; k > 0 && !b.Empty() && b.min.data <= limit; k-- {
+		x, _ := b.DeleteMin()
+		if !yield(x) {
+			return
+		}
+	}
+}
+
+// CountUpTo returns the number of elements in the BinomialHeap
+// whose keys are at most limit.
+//
+// Actual cost is O(k lg n + r), where k is the result
+// and r is the number of trees.
+func (b *BinomialHeap) CountUpTo(limit int) int {
+	count := 0
+	b.visitUpTo(limit, func(*bHeapNode) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// VisitUpTo calls visit on the nodes of the BinomialHeap whose keys are at most
+// limit, in no particular order, until visit returns false.
+// Only the subtrees whose roots qualify are walked, and nothing is removed.
+// The heap must not be modified during the visit.
+//
+// Actual cost is O(k lg n + r), where k is the number of visited nodes
+// and r is the number of trees.
+func (b *BinomialHeap) VisitUpTo(limit int, visit func(DataNode) bool) {
+	b.visitUpTo(limit, func(x *bHeapNode) bool {
+		x.escaped = true
+		return visit(x)
+	})
+}
+
+// visitUpTo is like VisitUpTo, but does not hand the nodes out,
+// so they can still be recycled in pooled mode.
+func (b *BinomialHeap) visitUpTo(limit int, visit func(*bHeapNode) bool) {
+	if b.Empty() {
+		return
+	}
+
+	var stack []*bHeapNode
+	push := func(list *bHeapNode) {
+		for x := list; x != nil; x = x.next {
+			if x.data <= limit {
+				stack = append(stack, x)
+			}
+			if x.next == list {
+				break
+			}
+		}
+	}
+
+	push(b.min)
+	for len(stack) > 0 {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !visit(x) {
+			return
+		}
+		push(x.child)
+	}
 }
 
 // DeleteMin pops the minimum from the BinomialHeap then returns it,
@@ -59,9 +301,11 @@ func (b *BinomialHeap) DeleteMin() (int, error) {
 	defer func() { b.n-- }()
 
 	minValue := b.min.data
+	b.min.removed = true
+	defer b.release(b.min)
 
 	if isOnly(b.min) {
-		b.min = findMinNode(b.min.child).(*bHeapNode)
+		b.min = findMinNode(b.min.child, b.stable)
 		return minValue, nil
 	}
 
@@ -84,14 +328,77 @@ func (b *BinomialHeap) DeleteMin() (int, error) {
 	return minValue, nil
 }
 
+// Clone returns a deep copy of b, which shares no nodes with b.
+//
+// Actual cost is O(n).
+func (b *BinomialHeap) Clone() *BinomialHeap {
+	c, _ := b.clone(false)
+	return c
+}
+
+// CloneWithHandles is like Clone, but also returns a mapping from the
+// handles of b to the corresponding handles of the copy.
+//
+// Actual cost is O(n).
+func (b *BinomialHeap) CloneWithHandles() (*BinomialHeap, map[DataNode]DataNode) {
+	return b.clone(true)
+}
+
+func (b *BinomialHeap) clone(withHandles bool) (*BinomialHeap, map[DataNode]DataNode) {
+	c := &BinomialHeap{n: b.n, stable: b.stable}
+	if b.pool != nil {
+		c.pool = &pool.Pool[bHeapNode]{}
+	}
+	var handles map[DataNode]DataNode
+	if withHandles {
+		handles = make(map[DataNode]DataNode, b.n)
+	}
+
+	// copyList copies the circular list starting from list and all their
+	// subtrees, and returns the copy of list
+	var copyList func(list *bHeapNode) *bHeapNode
+	copyList = func(list *bHeapNode) *bHeapNode {
+		var head *bHeapNode
+		for x := list; ; x = x.next {
+			y := &bHeapNode{data: x.data, seq: x.seq, degree: x.degree}
+			if head == nil {
+				head, y.prev, y.next = y, y, y
+			} else {
+				head.AddSibling(y)
+			}
+			if x.child != nil {
+				y.child = copyList(x.child)
+			}
+			if withHandles {
+				y.escaped = true
+				handles[x] = y
+			}
+
+			if x.next == list {
+				break
+			}
+		}
+		return head
+	}
+
+	if b.min != nil {
+		c.min = copyList(b.min)
+	}
+	return c, handles
+}
+
 func (b *BinomialHeap) mergeSameDegreeTrees() []*bHeapNode {
 	maxDegree := int(math.Log2(float64(b.n))) + 1
-	trees := make([]*bHeapNode, maxDegree)
+	if cap(b.trees) < maxDegree {
+		b.trees = make([]*bHeapNode, maxDegree)
+	}
+	trees := b.trees[:maxDegree]
+	clear(trees)
 
 	for p, next := b.min, b.min.next; ; p, next = next, next.next {
 		d := p.degree
 		for ; trees[d] != nil; d++ {
-			p = joinMinTrees(p, trees[d]).(*bHeapNode)
+			p = joinMinTrees(p, trees[d], b.stable)
 			trees[d] = nil
 		}
 		trees[d] = p
@@ -113,7 +420,7 @@ func (b *BinomialHeap) relink(trees []*bHeapNode) {
 			b.min, node.next, node.prev = node, node, node
 		} else {
 			b.min.AddSibling(node)
-			if node.data < b.min.data {
+			if b.less(node, b.min) {
 				b.min = node
 			}
 		}
@@ -121,11 +428,15 @@ func (b *BinomialHeap) relink(trees []*bHeapNode) {
 }
 
 // Meld two BinomialHeap and leave other empty,
-// error if the underlying type of other is not BinomialHeap.
+// error if the underlying type of other is not BinomialHeap
+// or only one of them is in stable mode.
 //
 // Amortized cost is O(1).
 func (b *BinomialHeap) Meld(other MeldablePQ) error {
 	if other, ok := other.(*BinomialHeap); ok {
+		if other.stable != b.stable {
+			return fmt.Errorf("cannot meld stable and non-stable heaps")
+		}
 		if other.Empty() {
 			return nil
 		}
@@ -137,7 +448,7 @@ func (b *BinomialHeap) Meld(other MeldablePQ) error {
 
 		mergeLists(b.min, other.min)
 
-		if other.min.data < b.min.data {
+		if b.less(other.min, b.min) {
 			b.min = other.min
 		}
 		b.n += other.n
@@ -1,7 +1,10 @@
 package priorityqueue
 
 import (
+	"math/rand"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestBinomialHeap_Empty(t *testing.T) {
@@ -180,3 +183,469 @@ func TestBinomialHeap_Meld3(t *testing.T) {
 		t.Fatal("should report error when other is not Binomial Heap")
 	}
 }
+
+func TestBinomialHeap_Stable(t *testing.T) {
+	b := BinomialHeap{}
+	if err := b.SetStable(true); err != nil {
+		t.Fatal(err)
+	}
+
+	var hd []DataNode
+	for i := 0; i < 100; i++ {
+		hd = append(hd, b.Insert(i%4))
+	}
+
+	for k := 0; k < 4; k++ {
+		for i := k; i < len(hd); i += 4 {
+			if b.min != hd[i] {
+				t.Fatalf("element #%d should be the minimum", i)
+			}
+			if p, _ := b.DeleteMin(); p != k {
+				t.Fatalf("got: %d, expect: %d", p, k)
+			}
+		}
+	}
+
+	b.Insert(1)
+	if err := b.SetStable(false); err == nil {
+		t.Fatal("should report error when b is not empty")
+	}
+}
+
+func TestBinomialHeap_StableMeld(t *testing.T) {
+	var b1, b2, b3 BinomialHeap
+	_ = b1.SetStable(true)
+	_ = b2.SetStable(true)
+
+	var hd []DataNode
+	for i := 0; i < 64; i++ {
+		if i%3 == 0 {
+			hd = append(hd, b1.Insert(7))
+		} else {
+			hd = append(hd, b2.Insert(7))
+		}
+	}
+	// consolidate both heaps before melding
+	b1.Insert(0)
+	b2.Insert(0)
+	_, _ = b1.DeleteMin()
+	_, _ = b2.DeleteMin()
+
+	if err := b1.Meld(&b2); err != nil {
+		t.Fatal(err)
+	}
+	for i, h := range hd {
+		if b1.min != h {
+			t.Fatalf("element #%d should be the minimum", i)
+		}
+		_, _ = b1.DeleteMin()
+	}
+
+	if err := b1.Meld(&b3); err == nil {
+		t.Fatal("should report error when only one heap is stable")
+	}
+}
+
+func TestBinomialHeap_MinNode(t *testing.T) {
+	b := BinomialHeap{}
+	if _, err := b.MinNode(); err == nil {
+		t.Fatal("should report that b is empty")
+	}
+
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = b.Insert(v)
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		h, err := b.MinNode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != hd[ans] {
+			t.Fatalf("got: %d, expect: %d", h.Data(), ans)
+		}
+		if k, err := Key(h); err != nil || k != ans {
+			t.Fatalf("got: %d (%v), expect: %d", k, err, ans)
+		}
+
+		if _, err := b.DeleteMin(); err != nil {
+			t.Fatal(err)
+		}
+		if Valid(h) {
+			t.Fatal("the popped node should be invalid")
+		}
+		if _, err := Key(h); err == nil {
+			t.Fatal("should report that the node is not in the queue")
+		}
+	}
+}
+
+func TestBinomialHeap_InsertMany(t *testing.T) {
+	b := BinomialHeap{}
+	b.Insert(10)
+	b.Insert(0)
+
+	hd := b.InsertMany([]int{5, 2, 7, 6, 9, 1, 8, 4, 3})
+	for i, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		if hd[i].Data() != v {
+			t.Fatalf("got: %d, expect: %d", hd[i].Data(), v)
+		}
+	}
+	if len(b.InsertMany(nil)) != 0 {
+		t.Fatal("nothing should be inserted")
+	}
+
+	for ans := 0; ans <= 10; ans++ {
+		p, err := b.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !b.Empty() {
+		t.Fatal("b should be empty")
+	}
+}
+
+func TestBinomialHeap_DeleteMinN(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	b := BinomialHeap{}
+	var keys []int
+	for i := 0; i < 1024; i++ {
+		x := rng.Intn(256)
+		keys = append(keys, x)
+	}
+	b.InsertMany(keys[:512])
+	for _, x := range keys[512:] {
+		b.Insert(x)
+	}
+	slices.Sort(keys)
+
+	for len(keys) > 0 {
+		k := rng.Intn(64)
+		got := b.DeleteMinN(k)
+		want := keys[:min(k, len(keys))]
+		if !slices.Equal(got, want) {
+			t.Fatalf("got: %v, expect: %v", got, want)
+		}
+		keys = keys[len(want):]
+
+		if x := rng.Intn(256); rng.Intn(2) == 0 {
+			b.Insert(x)
+			i, _ := slices.BinarySearch(keys, x)
+			keys = slices.Insert(keys, i, x)
+		}
+	}
+
+	if !b.Empty() {
+		t.Fatal("b should be empty")
+	}
+	if got := b.DeleteMinN(1); got != nil {
+		t.Fatalf("got: %v, expect: []", got)
+	}
+}
+
+func BenchmarkBinomialHeap_Insert(b *testing.B) {
+	xs := rand.Perm(1 << 12)
+	for i := 0; i < b.N; i++ {
+		h := BinomialHeap{}
+		for _, x := range xs {
+			h.Insert(x)
+		}
+	}
+}
+
+func BenchmarkBinomialHeap_InsertMany(b *testing.B) {
+	xs := rand.Perm(1 << 12)
+	for i := 0; i < b.N; i++ {
+		h := BinomialHeap{}
+		h.InsertMany(xs)
+	}
+}
+
+func BenchmarkBinomialHeap_DeleteMin(b *testing.B) {
+	xs := rand.Perm(1 << 12)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		h := BinomialHeap{}
+		h.InsertMany(xs)
+		b.StartTimer()
+
+		for j := 0; j < 1<<8; j++ {
+			if _, err := h.DeleteMin(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkBinomialHeap_DeleteMinN(b *testing.B) {
+	xs := rand.Perm(1 << 12)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		h := BinomialHeap{}
+		h.InsertMany(xs)
+		b.StartTimer()
+
+		h.DeleteMinN(1 << 8)
+	}
+}
+
+func TestBinomialHeap_ExtractUpTo(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	b := BinomialHeap{}
+	var keys []int
+	for i := 0; i < 1024; i++ {
+		x := rng.Intn(4096)
+		b.Insert(x)
+		keys = append(keys, x)
+	}
+	slices.Sort(keys)
+
+	for limit := -1; len(keys) > 0; limit += rng.Intn(256) {
+		if got := b.CountUpTo(limit); got != countUpTo(keys, limit) {
+			t.Fatalf("got: %d, expect: %d", got, countUpTo(keys, limit))
+		}
+
+		got := b.ExtractUpTo(limit)
+		want := keys[:countUpTo(keys, limit)]
+		if !slices.Equal(got, want) {
+			t.Fatalf("got: %v, expect: %v", got, want)
+		}
+		keys = keys[len(want):]
+
+		if p, err := b.Min(); len(keys) > 0 && (err != nil || p != keys[0]) {
+			t.Fatalf("got: %d (%v), expect: %d", p, err, keys[0])
+		}
+	}
+
+	if !b.Empty() {
+		t.Fatal("b should be empty")
+	}
+}
+
+func TestBinomialHeap_ExtractUpToSeq(t *testing.T) {
+	b := BinomialHeap{}
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		b.Insert(v)
+	}
+
+	var got []int
+	for x := range b.ExtractUpToSeq(6) {
+		got = append(got, x)
+		if x == 3 {
+			break
+		}
+	}
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("got: %v, expect: [1 2 3]", got)
+	}
+
+	for ans := 4; ans < 10; ans++ {
+		p, err := b.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !b.Empty() {
+		t.Fatal("b should be empty")
+	}
+}
+
+func TestBinomialHeap_VisitUpTo(t *testing.T) {
+	b := BinomialHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3, 0} {
+		hd[v] = b.Insert(v)
+	}
+	b.DeleteMin()
+
+	visited := map[DataNode]bool{}
+	b.VisitUpTo(4, func(h DataNode) bool {
+		visited[h] = true
+		return true
+	})
+	if len(visited) != 4 {
+		t.Fatalf("got: %d, expect: 4", len(visited))
+	}
+	for v := 1; v <= 4; v++ {
+		if !visited[hd[v]] {
+			t.Fatalf("%d should be visited", v)
+		}
+	}
+
+	count := 0
+	b.VisitUpTo(9, func(DataNode) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Fatalf("got: %d, expect: 3", count)
+	}
+
+	if n := b.CountUpTo(100); n != 9 {
+		t.Fatalf("got: %d, expect: 9", n)
+	}
+}
+
+func TestBinomialHeap_Clone(t *testing.T) {
+	b := BinomialHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3, 0} {
+		hd[v] = b.Insert(v)
+	}
+	b.DeleteMin()
+
+	c, handles := b.CloneWithHandles()
+	if len(handles) != 9 {
+		t.Fatalf("got: %d, expect: 9", len(handles))
+	}
+	for v := 1; v < 10; v++ {
+		if h := handles[hd[v]]; h == nil || h == hd[v] || h.Data() != v {
+			t.Fatalf("the handle of %d is not copied correctly", v)
+		}
+	}
+
+	// the copy is not affected by the operations on the original heap
+	b.DeleteMinN(5)
+	b.Insert(-1)
+	for ans := 1; ans < 10; ans++ {
+		p, err := c.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+	if !c.Empty() {
+		t.Fatal("c should be empty")
+	}
+
+	for _, ans := range []int{-1, 6, 7, 8, 9} {
+		p, err := b.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if e := (&BinomialHeap{}).Clone(); !e.Empty() {
+		t.Fatal("the copy of an empty heap should be empty")
+	}
+}
+
+var _ ThresholdPQ = (*BinomialHeap)(nil)
+
+func TestBinomialHeap_CountUpToPooled(t *testing.T) {
+	h := BinomialHeap{}
+	h.SetPooled(true)
+	for _, x := range rand.Perm(64) {
+		h.Push(x)
+	}
+	_, _ = h.DeleteMin()
+
+	if got := h.CountUpTo(31); got != 31 {
+		t.Fatalf("got: %d, expect: %d", got, 31)
+	}
+	// counting hands no node out, so the popped nodes are still recycled
+	h.visitUpTo(64, func(x *bHeapNode) bool {
+		if x.escaped {
+			t.Fatalf("%d should not be escaped", x.data)
+		}
+		return true
+	})
+	free := h.pool.Free()
+	_, _ = h.DeleteMin()
+	if h.pool.Free() != free+1 {
+		t.Fatalf("got: %d, expect: %d", h.pool.Free(), free+1)
+	}
+}
+
+func TestBinomialHeap_Pooled(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	b := BinomialHeap{}
+	b.SetPooled(true)
+
+	var keys []int
+	var hd []DataNode
+	for i := 0; i < 4096; i++ {
+		if len(keys) == 0 || rng.Intn(3) > 0 {
+			x := rng.Intn(1 << 12)
+			if rng.Intn(4) == 0 {
+				hd = append(hd, b.Insert(x))
+			} else {
+				b.Push(x)
+			}
+			i, _ := slices.BinarySearch(keys, x)
+			keys = slices.Insert(keys, i, x)
+			continue
+		}
+
+		p, err := b.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != keys[0] {
+			t.Fatalf("got: %d, expect: %d", p, keys[0])
+		}
+		keys = keys[1:]
+	}
+
+	if got := b.DeleteMinN(len(keys)); !slices.Equal(got, keys) {
+		t.Fatalf("got: %v, expect: %v", got, keys)
+	}
+
+	// the popped nodes with handles are never recycled
+	for _, h := range hd {
+		if Valid(h) {
+			t.Fatalf("the handle of %d should be invalid", h.Data())
+		}
+	}
+}
+
+func BenchmarkBinomialHeap_InsertDeleteMin(b *testing.B) {
+	b.ReportAllocs()
+	h := BinomialHeap{}
+	for _, x := range rand.Perm(1 << 10) {
+		h.Insert(x)
+	}
+
+	for i := 0; i < b.N; i++ {
+		p, _ := h.DeleteMin()
+		h.Insert(p + 1<<10)
+	}
+}
+
+func BenchmarkBinomialHeap_PooledPushDeleteMin(b *testing.B) {
+	b.ReportAllocs()
+	h := BinomialHeap{}
+	h.SetPooled(true)
+	for _, x := range rand.Perm(1 << 10) {
+		h.Push(x)
+	}
+
+	for i := 0; i < b.N; i++ {
+		p, _ := h.DeleteMin()
+		h.Push(p + 1<<10)
+	}
+}
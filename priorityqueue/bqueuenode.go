@@ -0,0 +1,23 @@
+package priorityqueue
+
+type bQueueNode struct {
+	data       int
+	prev, next *bQueueNode
+	queue      *BucketQueue // the queue holding the node
+	removed    bool
+}
+
+func (n *bQueueNode) Data() int {
+	return n.data
+}
+
+func (n *bQueueNode) valid() bool {
+	return n != nil && !n.removed
+}
+
+func (n *bQueueNode) AddSibling(s *bQueueNode) {
+	s.next = n
+	s.prev = n.prev
+	n.prev.next = s
+	n.prev = s
+}
@@ -0,0 +1,229 @@
+package priorityqueue
+
+import "fmt"
+
+// BucketQueue keeps one bucket per possible key, which makes it suitable
+// for keys drawn from a small, fixed integer range.
+// It must be initialized by Init before use.
+type BucketQueue struct {
+	lo      int
+	buckets []*bQueueNode
+	cur     int // index of the lowest bucket that may be non-empty
+	n       int
+}
+
+// Init sets up b to hold keys in the range [lo, hi) and empties it.
+func (b *BucketQueue) Init(lo, hi int) {
+	b.lo = lo
+	b.buckets = make([]*bQueueNode, max(hi-lo, 0))
+	b.cur = 0
+	b.n = 0
+}
+
+func (b *BucketQueue) inRange(x int) bool {
+	return x >= b.lo && x < b.lo+len(b.buckets)
+}
+
+// Min peeks and returns the minimum of the queue.
+//
+// Amortized cost is O(C), where C is the size of the key range.
+func (b *BucketQueue) Min() (int, error) {
+	if b.Empty() {
+		return 0, fmt.Errorf("queue is empty")
+	}
+	return b.minBucket().data, nil
+}
+
+// MinNode peeks and returns the node of the minimum of the queue.
+//
+// Amortized cost is O(C), where C is the size of the key range.
+func (b *BucketQueue) MinNode() (DataNode, error) {
+	if b.Empty() {
+		return nil, fmt.Errorf("queue is empty")
+	}
+	return b.minBucket(), nil
+}
+
+// Empty returns whether the queue is empty or not.
+func (b *BucketQueue) Empty() bool {
+	return b.n == 0
+}
+
+// Insert x into the BucketQueue and return the inserted node,
+// panic if x is outside the range given to Init.
+//
+// Actual cost is O(1).
+func (b *BucketQueue) Insert(x int) DataNode {
+	if !b.inRange(x) {
+		panic(fmt.Sprintf("key %d is out of range [%d, %d)", x, b.lo, b.lo+len(b.buckets)))
+	}
+
+	node := &bQueueNode{data: x, queue: b}
+	b.link(node)
+	b.n++
+	return node
+}
+
+// DeleteMin pops the minimum from the BucketQueue then returns it,
+// error if the queue is empty.
+//
+// Amortized cost is O(C), where C is the size of the key range.
+func (b *BucketQueue) DeleteMin() (int, error) {
+	if b.Empty() {
+		return 0, fmt.Errorf("cannot delete-min from empty bucket queue")
+	}
+
+	node := b.minBucket()
+	b.unlink(node)
+	node.removed = true
+	b.n--
+	return node.data, nil
+}
+
+// Delete the specified arbitrary node in the BucketQueue b, error if b is
+// empty, the target is no longer in b, the target belongs to another queue
+// or the target's type is incorrect.
+//
+// Actual cost is O(1).
+func (b *BucketQueue) Delete(target DataNode) (int, error) {
+	if b.Empty() {
+		return 0, fmt.Errorf("cannot delete from empty bucket queue")
+	}
+
+	if target, ok := target.(*bQueueNode); ok {
+		if !target.valid() {
+			return 0, fmt.Errorf("handle is no longer in the queue")
+		}
+		if target.queue != b {
+			return 0, fmt.Errorf("handle belongs to another queue")
+		}
+		b.unlink(target)
+		target.removed = true
+		b.n--
+		return target.data, nil
+	}
+
+	return 0, fmt.Errorf("incorrect type of target")
+}
+
+// DecreaseKey decrease the key of the specified node in b,
+// error if key is greater than original key, key is out of range,
+// the target is no longer in b, the target belongs to another queue
+// or the target's type is incorrect.
+//
+// Actual cost is O(1).
+func (b *BucketQueue) DecreaseKey(target DataNode, key int) error {
+	if target, ok := target.(*bQueueNode); ok {
+		if !target.valid() {
+			return fmt.Errorf("handle is no longer in the queue")
+		}
+		if target.queue != b {
+			return fmt.Errorf("handle belongs to another queue")
+		}
+		if target.data < key {
+			return fmt.Errorf("new key is greater than original key")
+		}
+		if !b.inRange(key) {
+			return fmt.Errorf("key %d is out of range", key)
+		}
+
+		b.unlink(target)
+		target.data = key
+		b.link(target)
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
+// IncreaseKey increase the key of the specified node in b,
+// error if key is less than original key, out of range,
+// the target is no longer in b, the target belongs to another queue
+// or the target's type is incorrect.
+//
+// Actual cost is O(1).
+func (b *BucketQueue) IncreaseKey(target DataNode, key int) error {
+	if target, ok := target.(*bQueueNode); ok {
+		if !target.valid() {
+			return fmt.Errorf("handle is no longer in the queue")
+		}
+		if target.queue != b {
+			return fmt.Errorf("handle belongs to another queue")
+		}
+		if key < target.data {
+			return fmt.Errorf("new key is less than original key")
+		}
+		if !b.inRange(key) {
+			return fmt.Errorf("key %d is out of range", key)
+		}
+
+		b.unlink(target)
+		target.data = key
+		b.link(target)
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
+// UpdateKey sets the key of the specified node in b to key,
+// by DecreaseKey or IncreaseKey depending on the original key,
+// error if the target is no longer in b, the target belongs to another
+// queue or the target's type is incorrect.
+func (b *BucketQueue) UpdateKey(target DataNode, key int) error {
+	if target, ok := target.(*bQueueNode); ok {
+		if !target.valid() {
+			return fmt.Errorf("handle is no longer in the queue")
+		}
+		if target.queue != b {
+			return fmt.Errorf("handle belongs to another queue")
+		}
+		switch {
+		case key < target.data:
+			return b.DecreaseKey(target, key)
+		case key > target.data:
+			return b.IncreaseKey(target, key)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
+// minBucket advances b.cur to the first non-empty bucket and returns its head.
+// b must not be empty.
+func (b *BucketQueue) minBucket() *bQueueNode {
+	for b.buckets[b.cur] == nil {
+		b.cur++
+	}
+	return b.buckets[b.cur]
+}
+
+// link appends node to the tail of its bucket, so nodes of equal key
+// leave the queue in insertion order.
+func (b *BucketQueue) link(node *bQueueNode) {
+	i := node.data - b.lo
+	if head := b.buckets[i]; head != nil {
+		head.AddSibling(node)
+	} else {
+		b.buckets[i], node.prev, node.next = node, node, node
+	}
+
+	if i < b.cur {
+		b.cur = i
+	}
+}
+
+func (b *BucketQueue) unlink(node *bQueueNode) {
+	i := node.data - b.lo
+	if node.next == node {
+		b.buckets[i] = nil
+	} else {
+		if b.buckets[i] == node {
+			b.buckets[i] = node.next
+		}
+		node.prev.next = node.next
+		node.next.prev = node.prev
+	}
+	node.prev, node.next = nil, nil
+}
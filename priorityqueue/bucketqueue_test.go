@@ -0,0 +1,421 @@
+package priorityqueue
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestBucketQueue_Empty(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(0, 10)
+	if !b.Empty() {
+		t.Fatal("b.Empty() should be true")
+	}
+
+	b.Insert(1)
+	b.Insert(2)
+	if b.Empty() {
+		t.Fatal("b.Empty() should be false after insertion")
+	}
+
+	_, _ = b.DeleteMin()
+	_, _ = b.DeleteMin()
+	if !b.Empty() {
+		t.Fatal("b.Empty() should be true after delete all elements")
+	}
+}
+
+func TestBucketQueue_Insert(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(0, 10)
+
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		hd := b.Insert(v)
+		if _, ok := hd.(*bQueueNode); !ok {
+			t.Fatal("incorrect underlying type")
+		}
+		if hd.Data() != v {
+			t.Fatalf("got: %d, expect: %d", hd.Data(), v)
+		}
+	}
+
+	if b.n != 5 {
+		t.Fatal("b.n should be 5, got", b.n)
+	}
+}
+
+func TestBucketQueue_Insert2(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(-5, 5)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("should panic when key is out of range")
+		}
+	}()
+	b.Insert(5)
+}
+
+func TestBucketQueue_DeleteMin(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(0, 10)
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		b.Insert(v)
+	}
+	for ans := 1; !b.Empty(); ans++ {
+		v, err := b.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != ans {
+			t.Fatalf("got: %d, expect: %d", v, ans)
+		}
+	}
+	_, err := b.DeleteMin()
+	if err == nil {
+		t.Fatal("should report error when b is empty")
+	}
+	if b.n != 0 {
+		t.Fatal("b.n should be 0")
+	}
+}
+
+func TestBucketQueue_Min(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(0, 10)
+
+	_, err := b.Min()
+	if err == nil {
+		t.Fatal("should report error when b is empty")
+	}
+
+	for _, v := range [][2]int{{5, 5}, {2, 2}, {4, 2}, {3, 2}, {1, 1}, {6, 1}} {
+		x, a := v[0], v[1]
+		b.Insert(x)
+		if y, err := b.Min(); y != a {
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Fatalf("got: %d, expect: %d", y, a)
+		}
+	}
+}
+
+func TestBucketQueue_Delete(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(0, 10)
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = b.Insert(v)
+	}
+
+	for _, v := range []int{1, 8, 4, 2, 3} {
+		p, err := b.Delete(hd[v])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != v {
+			t.Fatalf("got: %d, expect: %d", p, v)
+		}
+	}
+
+	for _, ans := range []int{5, 6, 7, 9} {
+		p, err := b.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Fatalf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	_, err := b.Delete(hd[5])
+	if err == nil {
+		t.Fatal("should report empty deletion")
+	}
+
+	b.Insert(5)
+	_, err = b.Delete(&bHeapNode{data: 5})
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+}
+
+func TestBucketQueue_DecreaseKey(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(-10, 10)
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = b.Insert(v)
+	}
+
+	for _, v := range []int{1, 8, 4, 2, 3} {
+		err := b.DecreaseKey(hd[v], -v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, ans := range []int{-8, -4, -3, -2, -1, 5, 6, 7, 9} {
+		p, err := b.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+}
+
+func TestBucketQueue_DecreaseKey2(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(0, 10)
+	hd := b.Insert(5)
+
+	if err := b.DecreaseKey(hd, 6); err == nil {
+		t.Fatal("increase a key is not valid")
+	}
+	if err := b.DecreaseKey(hd, -1); err == nil {
+		t.Fatal("should report key out of range")
+	}
+	if err := b.DecreaseKey(&bHeapNode{data: 5}, 1); err == nil {
+		t.Fatal("should report incorrect type")
+	}
+}
+
+func TestBucketQueue_StaleHandle(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(0, 10)
+	hd := b.Insert(3)
+	b.Insert(5)
+	if _, err := b.DeleteMin(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.Delete(hd); err == nil {
+		t.Fatal("should report that the handle is no longer in the queue")
+	}
+	if err := b.DecreaseKey(hd, 1); err == nil {
+		t.Fatal("should report that the handle is no longer in the queue")
+	}
+	if err := b.IncreaseKey(hd, 7); err == nil {
+		t.Fatal("should report that the handle is no longer in the queue")
+	}
+	if err := b.UpdateKey(hd, 3); err == nil {
+		t.Fatal("should report that the handle is no longer in the queue")
+	}
+	if p, _ := b.Min(); p != 5 || b.n != 1 {
+		t.Fatalf("got: %d, expect: 5", p)
+	}
+}
+
+func TestBucketQueue_ForeignHandle(t *testing.T) {
+	a, b := BucketQueue{}, BucketQueue{}
+	a.Init(0, 10)
+	b.Init(0, 10)
+	ha := a.Insert(3)
+	b.Insert(3)
+
+	if _, err := b.Delete(ha); err == nil {
+		t.Fatal("should report that the handle belongs to another queue")
+	}
+	if err := b.DecreaseKey(ha, 1); err == nil {
+		t.Fatal("should report that the handle belongs to another queue")
+	}
+	if err := b.IncreaseKey(ha, 5); err == nil {
+		t.Fatal("should report that the handle belongs to another queue")
+	}
+	if err := b.UpdateKey(ha, 3); err == nil {
+		t.Fatal("should report that the handle belongs to another queue")
+	}
+
+	for _, q := range []*BucketQueue{&a, &b} {
+		if p, _ := q.Min(); p != 3 || q.n != 1 {
+			t.Fatalf("got: %d with %d elements, expect: 3 with 1 element", p, q.n)
+		}
+	}
+}
+
+func TestBucketQueue_Random(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	b := BucketQueue{}
+	b.Init(0, 32)
+	count := make([]int, 32)
+	for i := 0; i < 512; i++ {
+		v := rng.Intn(32)
+		b.Insert(v)
+		count[v]++
+	}
+
+	for ans := 0; ans < 32; ans++ {
+		for ; count[ans] > 0; count[ans]-- {
+			p, err := b.DeleteMin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p != ans {
+				t.Fatalf("got: %d, expect: %d", p, ans)
+			}
+		}
+	}
+
+	if !b.Empty() {
+		t.Fatal("b should be empty")
+	}
+}
+
+func TestBucketQueue_IncreaseKey(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(-1, 32)
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = b.Insert(v)
+	}
+
+	// consolidate the trees so that some nodes have children
+	b.Insert(-1)
+	if p, _ := b.DeleteMin(); p != -1 {
+		t.Fatalf("got: %d, expect: -1", p)
+	}
+
+	for _, v := range []int{1, 8, 4, 2, 3} {
+		err := b.IncreaseKey(hd[v], v+10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hd[v].Data() != v+10 {
+			t.Fatalf("got: %d, expect: %d", hd[v].Data(), v+10)
+		}
+	}
+
+	// the handles stay valid after the keys are increased
+	if err := b.DecreaseKey(hd[8], 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ans := range []int{0, 5, 6, 7, 9, 11, 12, 13, 14} {
+		p, err := b.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !b.Empty() {
+		t.Fatal("b should be empty")
+	}
+}
+
+func TestBucketQueue_IncreaseKey2(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(-1, 32)
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6} {
+		hd[v] = b.Insert(v)
+	}
+
+	err := b.IncreaseKey(hd[5], 1)
+	if err == nil {
+		t.Fatal("decrease a key is not valid")
+	}
+
+	err = b.IncreaseKey(&fHeapNode{data: 5}, 10)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+
+	err = b.UpdateKey(&fHeapNode{data: 5}, 10)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+}
+
+func TestBucketQueue_RandomUpdateKey(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	b := BucketQueue{}
+	b.Init(-1, 1<<16)
+	live := map[DataNode]bool{}
+	var hd []DataNode
+
+	// keys are kept distinct so that the popped handle is known
+	for i := 0; i < 4096; i++ {
+		switch op := rng.Intn(10); {
+		case op < 4 || len(live) == 0:
+			h := b.Insert(rng.Intn(1<<4)<<12 | i)
+			hd = append(hd, h)
+			live[h] = true
+		case op < 8:
+			h := hd[rng.Intn(len(hd))]
+			if !live[h] {
+				continue
+			}
+			key := rng.Intn(1<<4)<<12 | i
+			if err := b.UpdateKey(h, key); err != nil {
+				t.Fatal(err)
+			}
+			if h.Data() != key {
+				t.Fatalf("got: %d, expect: %d", h.Data(), key)
+			}
+		default:
+			p, err := b.DeleteMin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want DataNode
+			for h := range live {
+				if h.Data() < p {
+					t.Fatalf("got: %d, but %d is smaller", p, h.Data())
+				}
+				if h.Data() == p {
+					want = h
+				}
+			}
+			delete(live, want)
+		}
+	}
+}
+
+func TestBucketQueue_MinNode(t *testing.T) {
+	b := BucketQueue{}
+	b.Init(0, 10)
+	if _, err := b.MinNode(); err == nil {
+		t.Fatal("should report that b is empty")
+	}
+
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = b.Insert(v)
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		h, err := b.MinNode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != hd[ans] {
+			t.Fatalf("got: %d, expect: %d", h.Data(), ans)
+		}
+		if k, err := Key(h); err != nil || k != ans {
+			t.Fatalf("got: %d (%v), expect: %d", k, err, ans)
+		}
+
+		if _, err := b.DeleteMin(); err != nil {
+			t.Fatal(err)
+		}
+		if Valid(h) {
+			t.Fatal("the popped node should be invalid")
+		}
+		if _, err := Key(h); err == nil {
+			t.Fatal("should report that the node is not in the queue")
+		}
+	}
+}
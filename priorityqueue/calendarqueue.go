@@ -0,0 +1,231 @@
+package priorityqueue
+
+import "fmt"
+
+const (
+	calendarMinBuckets  = 2
+	calendarSampleLimit = 25
+)
+
+// CalendarQueue implementation that is introduced in
+// 'Calendar Queues: A Fast O(1) Priority Queue Implementation
+// for the Simulation Event Set Problem' by R. Brown.
+//
+// Keys are hashed by time into a ring of buckets, each covering a day of
+// fixed width, and the ring is resized as the queue grows or shrinks.
+type CalendarQueue struct {
+	buckets    []*cQueueNode // each bucket is sorted in ascending order
+	width      int
+	n          int
+	lastBucket int // bucket where the previous minimum was found
+	bucketTop  int // exclusive upper bound of keys in lastBucket's current day
+}
+
+func (c *CalendarQueue) init(nBuckets, width int) {
+	c.buckets = make([]*cQueueNode, nBuckets)
+	c.width = width
+	c.lastBucket = 0
+	c.bucketTop = width
+}
+
+// Min peeks and returns the minimum of the queue.
+//
+// Amortized cost is O(1) for evenly distributed keys.
+func (c *CalendarQueue) Min() (int, error) {
+	if c.Empty() {
+		return 0, fmt.Errorf("queue is empty")
+	}
+	return c.findMin().data, nil
+}
+
+// MinNode peeks and returns the node of the minimum of the queue.
+//
+// Amortized cost is O(1) for evenly distributed keys.
+func (c *CalendarQueue) MinNode() (DataNode, error) {
+	if c.Empty() {
+		return nil, fmt.Errorf("queue is empty")
+	}
+	return c.findMin(), nil
+}
+
+// Empty returns whether the queue is empty or not.
+func (c *CalendarQueue) Empty() bool {
+	return c.n == 0
+}
+
+// Insert x into the CalendarQueue and return the inserted node.
+//
+// Amortized cost is O(1) for evenly distributed keys.
+func (c *CalendarQueue) Insert(x int) DataNode {
+	if c.buckets == nil {
+		c.init(calendarMinBuckets, 1)
+	}
+
+	node := &cQueueNode{data: x}
+	c.link(node)
+	c.n++
+
+	if c.n > 2*len(c.buckets) {
+		c.resize(2 * len(c.buckets))
+	}
+	return node
+}
+
+// DeleteMin pops the minimum from the CalendarQueue then returns it,
+// error if the queue is empty.
+//
+// Amortized cost is O(1) for evenly distributed keys.
+func (c *CalendarQueue) DeleteMin() (int, error) {
+	if c.Empty() {
+		return 0, fmt.Errorf("cannot delete-min from empty calendar queue")
+	}
+
+	node := c.findMin()
+	c.unlink(node)
+	node.removed = true
+	c.n--
+
+	if len(c.buckets) > calendarMinBuckets && c.n < len(c.buckets)/2 {
+		c.resize(len(c.buckets) / 2)
+	}
+	return node.data, nil
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func (c *CalendarQueue) bucketOf(key int) int {
+	i := floorDiv(key, c.width) % len(c.buckets)
+	if i < 0 {
+		i += len(c.buckets)
+	}
+	return i
+}
+
+// findMin scans at most one year of the calendar starting from lastBucket,
+// and falls back to a direct search over all bucket heads.
+// c must not be empty.
+//
+// Every key in the queue is at least bucketTop - width, so the first bucket
+// whose head falls into its current day holds the minimum.
+func (c *CalendarQueue) findMin() *cQueueNode {
+	i, top := c.lastBucket, c.bucketTop
+	for range c.buckets {
+		if head := c.buckets[i]; head != nil && head.data < top {
+			c.lastBucket, c.bucketTop = i, top
+			return head
+		}
+
+		i, top = i+1, top+c.width
+		if i == len(c.buckets) {
+			i = 0
+		}
+	}
+
+	var min *cQueueNode
+	for _, head := range c.buckets {
+		if head != nil && (min == nil || head.data < min.data) {
+			min = head
+		}
+	}
+	c.lastBucket = c.bucketOf(min.data)
+	c.bucketTop = (floorDiv(min.data, c.width) + 1) * c.width
+	return min
+}
+
+// link puts node into its bucket behind all nodes with key not greater than it.
+func (c *CalendarQueue) link(node *cQueueNode) {
+	i := c.bucketOf(node.data)
+
+	var prev *cQueueNode
+	next := c.buckets[i]
+	for ; next != nil && next.data <= node.data; prev, next = next, next.next {
+	}
+
+	node.prev, node.next = prev, next
+	if prev == nil {
+		c.buckets[i] = node
+	} else {
+		prev.next = node
+	}
+	if next != nil {
+		next.prev = node
+	}
+
+	// keep the invariant that no key lies before the current day
+	if node.data < c.bucketTop-c.width {
+		c.lastBucket = i
+		c.bucketTop = (floorDiv(node.data, c.width) + 1) * c.width
+	}
+}
+
+func (c *CalendarQueue) unlink(node *cQueueNode) {
+	if node.prev == nil {
+		c.buckets[c.bucketOf(node.data)] = node.next
+	} else {
+		node.prev.next = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	}
+	node.prev, node.next = nil, nil
+}
+
+// resize rebuilds the calendar with nBuckets buckets and a day width
+// estimated from the separation of the smallest keys.
+// Nodes are relinked rather than copied, so handles stay valid.
+func (c *CalendarQueue) resize(nBuckets int) {
+	nodes := make([]*cQueueNode, 0, c.n)
+
+	// the sample is taken in ascending order from the old calendar
+	sample := min(c.n, calendarSampleLimit)
+	for range sample {
+		node := c.findMin()
+		c.unlink(node)
+		nodes = append(nodes, node)
+	}
+	for _, head := range c.buckets {
+		for x := head; x != nil; {
+			next := x.next
+			nodes = append(nodes, x)
+			x = next
+		}
+	}
+
+	c.init(nBuckets, c.newWidth(nodes[:sample]))
+	if sample > 0 {
+		c.lastBucket = c.bucketOf(nodes[0].data)
+		c.bucketTop = (floorDiv(nodes[0].data, c.width) + 1) * c.width
+	}
+	for _, node := range nodes {
+		c.link(node)
+	}
+}
+
+func (c *CalendarQueue) newWidth(sample []*cQueueNode) int {
+	if len(sample) < 2 {
+		return c.width
+	}
+
+	total := sample[len(sample)-1].data - sample[0].data
+	avg := float64(total) / float64(len(sample)-1)
+
+	// ignore unusually large gaps, which would make the days too wide
+	sum, cnt := 0, 0
+	for i := 1; i < len(sample); i++ {
+		if gap := sample[i].data - sample[i-1].data; float64(gap) <= 2*avg {
+			sum += gap
+			cnt++
+		}
+	}
+	if cnt == 0 || sum == 0 {
+		return c.width
+	}
+
+	return max(1, (3*sum+cnt-1)/cnt)
+}
@@ -0,0 +1,171 @@
+package priorityqueue
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCalendarQueue_Empty(t *testing.T) {
+	c := CalendarQueue{}
+	if !c.Empty() {
+		t.Fatal("c.Empty() should be true")
+	}
+
+	c.Insert(1)
+	c.Insert(2)
+	if c.Empty() {
+		t.Fatal("c.Empty() should be false after insertion")
+	}
+
+	_, _ = c.DeleteMin()
+	_, _ = c.DeleteMin()
+	if !c.Empty() {
+		t.Fatal("c.Empty() should be true after delete all elements")
+	}
+}
+
+func TestCalendarQueue_Insert(t *testing.T) {
+	c := CalendarQueue{}
+
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		hd := c.Insert(v)
+		if _, ok := hd.(*cQueueNode); !ok {
+			t.Fatal("incorrect underlying type")
+		}
+		if hd.Data() != v {
+			t.Fatalf("got: %d, expect: %d", hd.Data(), v)
+		}
+	}
+
+	if c.n != 5 {
+		t.Fatal("c.n should be 5, got", c.n)
+	}
+}
+
+func TestCalendarQueue_DeleteMin(t *testing.T) {
+	c := CalendarQueue{}
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		c.Insert(v)
+	}
+	for ans := 1; !c.Empty(); ans++ {
+		v, err := c.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != ans {
+			t.Fatalf("got: %d, expect: %d", v, ans)
+		}
+	}
+	_, err := c.DeleteMin()
+	if err == nil {
+		t.Fatal("should report error when c is empty")
+	}
+	if c.n != 0 {
+		t.Fatal("c.n should be 0")
+	}
+}
+
+func TestCalendarQueue_Min(t *testing.T) {
+	c := CalendarQueue{}
+
+	_, err := c.Min()
+	if err == nil {
+		t.Fatal("should report error when c is empty")
+	}
+
+	for _, v := range [][2]int{{5, 5}, {2, 2}, {4, 2}, {3, 2}, {1, 1}, {6, 1}} {
+		x, a := v[0], v[1]
+		c.Insert(x)
+		if y, err := c.Min(); y != a {
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Fatalf("got: %d, expect: %d", y, a)
+		}
+	}
+}
+
+func TestCalendarQueue_Random(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	c := CalendarQueue{}
+	var ref []int
+
+	// simulate an event set: pop the earliest event and schedule new ones after it
+	now := 0
+	for i := 0; i < 4096; i++ {
+		if len(ref) > 0 && rng.Intn(3) == 0 {
+			p, err := c.DeleteMin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(ref)
+			if p != ref[0] {
+				t.Fatalf("got: %d, expect: %d", p, ref[0])
+			}
+			ref = ref[1:]
+			now = p
+		} else {
+			v := now + rng.Intn(1000) - 100
+			c.Insert(v)
+			ref = append(ref, v)
+		}
+	}
+
+	slices.Sort(ref)
+	for _, ans := range ref {
+		p, err := c.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Fatalf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !c.Empty() {
+		t.Fatal("c should be empty")
+	}
+	if len(c.buckets) != calendarMinBuckets {
+		t.Fatal("c should shrink back to", calendarMinBuckets, "buckets, got", len(c.buckets))
+	}
+}
+
+func TestCalendarQueue_MinNode(t *testing.T) {
+	c := CalendarQueue{}
+	if _, err := c.MinNode(); err == nil {
+		t.Fatal("should report that c is empty")
+	}
+
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = c.Insert(v)
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		h, err := c.MinNode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != hd[ans] {
+			t.Fatalf("got: %d, expect: %d", h.Data(), ans)
+		}
+		if k, err := Key(h); err != nil || k != ans {
+			t.Fatalf("got: %d (%v), expect: %d", k, err, ans)
+		}
+
+		if _, err := c.DeleteMin(); err != nil {
+			t.Fatal(err)
+		}
+		if Valid(h) {
+			t.Fatal("the popped node should be invalid")
+		}
+		if _, err := Key(h); err == nil {
+			t.Fatal("should report that the node is not in the queue")
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package priorityqueue
+
+type cQueueNode struct {
+	data       int
+	prev, next *cQueueNode
+	removed    bool
+}
+
+func (n *cQueueNode) Data() int {
+	return n.data
+}
+
+func (n *cQueueNode) valid() bool {
+	return n != nil && !n.removed
+}
@@ -0,0 +1,474 @@
+package priorityqueue
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// kinds of the records in the write-ahead log of DurableHeap
+const (
+	walInsert byte = iota + 1
+	walDeleteMin
+	walDecreaseKey
+	walDelete
+)
+
+// walRecordSize is the size of a record: kind, id, key and the checksum.
+const walRecordSize = 1 + 8 + 8 + 4
+
+const durableSnapshot = "snapshot"
+
+// DurableHeap is a FibonacciHeap that survives process crashes.
+//
+// Every operation is appended to a write-ahead log in its directory with a
+// checksum before it is applied, and the whole heap is written to a
+// snapshot every snapshotEvery operations, after which the log starts
+// over. Init recovers the state from the snapshot and the log, ignoring a
+// torn record at the end of the log, so the heap comes back exactly as it
+// was after the last complete operation.
+//
+// Elements are identified by IDs instead of handles, which stay the same
+// after recovery. Elements with equal keys are popped in the order of
+// their IDs.
+//
+// It must be initialized by Init before use.
+type DurableHeap struct {
+	heap  FibonacciHeap
+	nodes map[uint64]*fHeapNode // the seq of a node is its ID
+	next  uint64                // ID of the next inserted element
+
+	dir           string
+	gen           uint64 // generation of the current log
+	log           *os.File
+	ops           int // number of records in the current log
+	snapshotEvery int
+	err           error // first failure of the periodic snapshots
+}
+
+// Init opens the durable heap stored in dir, which is created if needed,
+// and recovers its state. A non-positive snapshotEvery disables
+// the periodic snapshots. A used d must be closed before Init.
+func (d *DurableHeap) Init(dir string, snapshotEvery int) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	*d = DurableHeap{
+		nodes:         map[uint64]*fHeapNode{},
+		next:          1,
+		dir:           dir,
+		snapshotEvery: snapshotEvery,
+	}
+	d.heap.stable = true
+
+	if err := d.loadSnapshot(); err != nil {
+		return err
+	}
+	if err := d.removeStaleLogs(); err != nil {
+		return err
+	}
+	if err := d.replayLog(); err != nil {
+		return err
+	}
+	return syncDir(d.dir)
+}
+
+// Close closes the log of d. The state stays in its directory.
+func (d *DurableHeap) Close() error {
+	if d.log == nil {
+		return nil
+	}
+	err := d.log.Close()
+	d.log = nil
+	return err
+}
+
+// Err returns the first error of the periodic snapshots. Such an error does
+// not fail the operation that took the snapshot, since the operation has
+// already been logged and applied, and the snapshot is tried again by the
+// next operation.
+func (d *DurableHeap) Err() error {
+	return d.err
+}
+
+// Sync commits the log to stable storage. Without Sync, the operations
+// survive process crashes but not necessarily power failures.
+func (d *DurableHeap) Sync() error {
+	return d.log.Sync()
+}
+
+// Empty returns whether the heap is empty or not.
+func (d *DurableHeap) Empty() bool {
+	return d.heap.Empty()
+}
+
+// Len returns the number of elements in the heap.
+func (d *DurableHeap) Len() int {
+	return d.heap.n
+}
+
+// Min peeks and returns the ID and the key of the minimum of the heap.
+func (d *DurableHeap) Min() (uint64, int, error) {
+	if d.Empty() {
+		return 0, 0, fmt.Errorf("heap is empty")
+	}
+	return d.heap.min.seq, d.heap.min.data, nil
+}
+
+// Key returns the key of the element with the given ID,
+// error if it is not in the heap.
+func (d *DurableHeap) Key(id uint64) (int, error) {
+	node, ok := d.nodes[id]
+	if !ok {
+		return 0, fmt.Errorf("element %d is not in the heap", id)
+	}
+	return node.data, nil
+}
+
+// Insert x into the DurableHeap and return the ID of the new element,
+// error if the operation cannot be logged, in which case it is not applied.
+// A failure of the periodic snapshot is reported by Err instead.
+//
+// Amortized cost is O(1) with one write to the log.
+func (d *DurableHeap) Insert(x int) (uint64, error) {
+	id := d.next
+	if err := d.append(walInsert, id, x); err != nil {
+		return 0, err
+	}
+	d.insert(id, x)
+	d.checkpoint()
+	return id, nil
+}
+
+// DeleteMin pops the minimum from the DurableHeap then returns its ID and
+// key, error if the heap is empty or the operation cannot be logged,
+// in which case it is not applied. A failure of the periodic snapshot is
+// reported by Err instead.
+//
+// Amortized cost is O(lg n) with one write to the log.
+func (d *DurableHeap) DeleteMin() (uint64, int, error) {
+	id, x, err := d.Min()
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot delete-min from empty durable heap")
+	}
+	if err := d.append(walDeleteMin, id, x); err != nil {
+		return 0, 0, err
+	}
+	d.deleteMin()
+	d.checkpoint()
+	return id, x, nil
+}
+
+// Delete the element with the given ID from the DurableHeap and return its
+// key, error if it is not in the heap or the operation cannot be logged,
+// in which case it is not applied. A failure of the periodic snapshot is
+// reported by Err instead.
+//
+// Amortized cost is O(lg n) with one write to the log.
+func (d *DurableHeap) Delete(id uint64) (int, error) {
+	x, err := d.Key(id)
+	if err != nil {
+		return 0, err
+	}
+	if err := d.append(walDelete, id, x); err != nil {
+		return 0, err
+	}
+	d.delete(id)
+	d.checkpoint()
+	return x, nil
+}
+
+// DecreaseKey decrease the key of the element with the given ID, error if
+// it is not in the heap, key is greater than its key or the operation
+// cannot be logged, in which case it is not applied. A failure of the
+// periodic snapshot is reported by Err instead.
+//
+// Amortized cost is O(1) with one write to the log.
+func (d *DurableHeap) DecreaseKey(id uint64, key int) error {
+	x, err := d.Key(id)
+	if err != nil {
+		return err
+	}
+	if x < key {
+		return fmt.Errorf("new key is greater than original key")
+	}
+	if err := d.append(walDecreaseKey, id, key); err != nil {
+		return err
+	}
+	_ = d.heap.DecreaseKey(d.nodes[id], key)
+	d.checkpoint()
+	return nil
+}
+
+func (d *DurableHeap) insert(id uint64, x int) {
+	node := &fHeapNode{data: x, seq: id, escaped: true}
+	d.heap.insertNode(node)
+	d.nodes[id] = node
+	d.next = max(d.next, id+1)
+}
+
+func (d *DurableHeap) deleteMin() uint64 {
+	id := d.heap.min.seq
+	_, _ = d.heap.DeleteMin()
+	delete(d.nodes, id)
+	return id
+}
+
+func (d *DurableHeap) delete(id uint64) {
+	_, _ = d.heap.Delete(d.nodes[id])
+	delete(d.nodes, id)
+}
+
+// checkpoint takes a snapshot if the log has grown long enough,
+// and keeps the first failure for Err.
+func (d *DurableHeap) checkpoint() {
+	if d.snapshotEvery > 0 && d.ops >= d.snapshotEvery {
+		if err := d.Snapshot(); err != nil && d.err == nil {
+			d.err = err
+		}
+	}
+}
+
+func (d *DurableHeap) logPath(gen uint64) string {
+	return filepath.Join(d.dir, fmt.Sprintf("wal-%d.log", gen))
+}
+
+// append writes a record to the log.
+func (d *DurableHeap) append(kind byte, id uint64, key int) error {
+	var buf [walRecordSize]byte
+	buf[0] = kind
+	binary.LittleEndian.PutUint64(buf[1:], id)
+	binary.LittleEndian.PutUint64(buf[9:], uint64(key))
+	binary.LittleEndian.PutUint32(buf[17:], crc32.ChecksumIEEE(buf[:17]))
+
+	if _, err := d.log.Write(buf[:]); err != nil {
+		// cut off the partial record, or the later records would be lost
+		end := int64(d.ops) * walRecordSize
+		if terr := d.log.Truncate(end); terr != nil {
+			return errors.Join(err, terr)
+		}
+		_, serr := d.log.Seek(end, io.SeekStart)
+		return errors.Join(err, serr)
+	}
+	d.ops++
+	return nil
+}
+
+// replayLog applies the complete records of the current log, cuts off a
+// torn record at its end, and opens it for appending. A bad record followed
+// by complete records is not torn but corrupted, and the log is left as is.
+func (d *DurableHeap) replayLog() error {
+	file, err := os.OpenFile(d.logPath(d.gen), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		return errors.Join(err, file.Close())
+	}
+
+	r := bufio.NewReader(file)
+	var buf [walRecordSize]byte
+	good := int64(0)
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return errors.Join(err, file.Close())
+		}
+		if crc32.ChecksumIEEE(buf[:17]) != binary.LittleEndian.Uint32(buf[17:]) {
+			if fi.Size()-good >= 2*walRecordSize {
+				return errors.Join(fmt.Errorf("corrupted log at offset %d: checksum mismatch", good), file.Close())
+			}
+			break
+		}
+
+		kind := buf[0]
+		id := binary.LittleEndian.Uint64(buf[1:])
+		key := int(binary.LittleEndian.Uint64(buf[9:]))
+		if err := d.redo(kind, id, key); err != nil {
+			return errors.Join(fmt.Errorf("corrupted log at offset %d: %w", good, err), file.Close())
+		}
+		good += walRecordSize
+		d.ops++
+	}
+
+	if err := file.Truncate(good); err != nil {
+		return errors.Join(err, file.Close())
+	}
+	if _, err := file.Seek(good, io.SeekStart); err != nil {
+		return errors.Join(err, file.Close())
+	}
+	d.log = file
+	return nil
+}
+
+// redo applies a record of the log.
+func (d *DurableHeap) redo(kind byte, id uint64, key int) error {
+	node, ok := d.nodes[id]
+	switch kind {
+	case walInsert:
+		if ok {
+			return fmt.Errorf("element %d is inserted twice", id)
+		}
+		d.insert(id, key)
+	case walDeleteMin:
+		if d.Empty() || d.heap.min.seq != id {
+			return fmt.Errorf("element %d is not the minimum", id)
+		}
+		d.deleteMin()
+	case walDecreaseKey:
+		if !ok || node.data < key {
+			return fmt.Errorf("cannot decrease the key of element %d", id)
+		}
+		_ = d.heap.DecreaseKey(node, key)
+	case walDelete:
+		if !ok {
+			return fmt.Errorf("element %d is not in the heap", id)
+		}
+		d.delete(id)
+	default:
+		return fmt.Errorf("unknown record kind %d", kind)
+	}
+	return nil
+}
+
+// Snapshot writes all elements of d to a new snapshot and starts a new log,
+// error if the snapshot cannot be written.
+//
+// The snapshot is written to a temporary file and renamed, so a crash at any
+// point leaves either the old snapshot with the old log, or the new snapshot
+// with the new log. If the rename fails, d keeps using the old log, and once
+// the snapshot is renamed, d uses the new log even if an error is returned.
+func (d *DurableHeap) Snapshot() error {
+	ids := make([]uint64, 0, len(d.nodes))
+	for id := range d.nodes {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	tmp := filepath.Join(d.dir, durableSnapshot+".tmp")
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	h := crc32.NewIEEE()
+	w := bufio.NewWriter(io.MultiWriter(file, h))
+	var buf [8]byte
+	put := func(x uint64) {
+		binary.LittleEndian.PutUint64(buf[:], x)
+		_, _ = w.Write(buf[:])
+	}
+
+	gen := d.gen + 1
+	put(gen)
+	put(d.next)
+	put(uint64(len(ids)))
+	for _, id := range ids {
+		put(id)
+		put(uint64(d.nodes[id].data))
+	}
+	err = w.Flush()
+	if err == nil {
+		binary.LittleEndian.PutUint32(buf[:4], h.Sum32())
+		_, err = file.Write(buf[:4])
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if err = errors.Join(err, file.Close()); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+
+	// create the new log before the rename, which is the commit point,
+	// so that nothing can fail between the rename and the switch to it
+	log, err := os.OpenFile(d.logPath(gen), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+	err = syncDir(d.dir)
+	if err == nil {
+		err = os.Rename(tmp, filepath.Join(d.dir, durableSnapshot))
+	}
+	if err != nil {
+		return errors.Join(err, log.Close(), os.Remove(d.logPath(gen)), os.Remove(tmp))
+	}
+
+	// switch to the new log, the old one is not needed any more
+	old, oldLog := d.logPath(d.gen), d.log
+	d.log, d.gen, d.ops = log, gen, 0
+	return errors.Join(syncDir(d.dir), oldLog.Close(), os.Remove(old), syncDir(d.dir))
+}
+
+// syncDir commits the entries of dir, such as the files created, renamed
+// or removed in it, to stable storage.
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	return errors.Join(file.Sync(), file.Close())
+}
+
+// loadSnapshot restores the elements and the generation of the log
+// from the snapshot if it exists.
+func (d *DurableHeap) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(d.dir, durableSnapshot))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	n := len(data) - 4
+	if n < 24 || (n-24)%16 != 0 ||
+		crc32.ChecksumIEEE(data[:n]) != binary.LittleEndian.Uint32(data[n:]) {
+		return fmt.Errorf("corrupted snapshot")
+	}
+	get := func(i int) uint64 {
+		return binary.LittleEndian.Uint64(data[8*i:])
+	}
+
+	d.gen, d.next = get(0), get(1)
+	if count := get(2); count != uint64(n-24)/16 {
+		return fmt.Errorf("corrupted snapshot")
+	}
+	for i := 3; 8*i < n; i += 2 {
+		d.insert(get(i), int(get(i+1)))
+	}
+	return nil
+}
+
+// removeStaleLogs removes the logs of other generations,
+// which may be left by a crash during Snapshot.
+func (d *DurableHeap) removeStaleLogs() error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, "wal-") || !strings.HasSuffix(name, ".log") {
+			continue
+		}
+		gen, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, "wal-"), ".log"), 10, 64)
+		if err == nil && gen != d.gen {
+			if err := os.Remove(filepath.Join(d.dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,514 @@
+package priorityqueue
+
+import (
+	"cmp"
+	"maps"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+type durableElem struct {
+	id  uint64
+	key int
+}
+
+// durableModel is the expected state of a DurableHeap.
+type durableModel map[uint64]int
+
+// sorted returns the elements in the order that DeleteMin pops them.
+func (m durableModel) sorted() []durableElem {
+	var res []durableElem
+	for id, key := range m {
+		res = append(res, durableElem{id, key})
+	}
+	slices.SortFunc(res, func(a, b durableElem) int {
+		return cmp.Or(cmp.Compare(a.key, b.key), cmp.Compare(a.id, b.id))
+	})
+	return res
+}
+
+// randomDurableOps applies n random operations to both d and m,
+// and returns the states of m after each operation, including the initial one.
+func randomDurableOps(t *testing.T, rng *rand.Rand, d *DurableHeap, m durableModel, n int) []durableModel {
+	states := []durableModel{maps.Clone(m)}
+	for len(states) <= n {
+		switch op := rng.Intn(10); {
+		case op < 5 || len(m) == 0:
+			key := rng.Intn(32)
+			id, err := d.Insert(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m[id] = key
+		case op < 7:
+			id, key, err := d.DeleteMin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := m.sorted()[0]; want != (durableElem{id, key}) {
+				t.Fatalf("got: %v, expect: %v", durableElem{id, key}, want)
+			}
+			delete(m, id)
+		case op < 9:
+			e := m.sorted()[rng.Intn(len(m))]
+			key := e.key - rng.Intn(8)
+			if err := d.DecreaseKey(e.id, key); err != nil {
+				t.Fatal(err)
+			}
+			m[e.id] = key
+		default:
+			e := m.sorted()[rng.Intn(len(m))]
+			key, err := d.Delete(e.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key != e.key {
+				t.Fatalf("got: %d, expect: %d", key, e.key)
+			}
+			delete(m, e.id)
+		}
+		states = append(states, maps.Clone(m))
+	}
+	return states
+}
+
+// checkDurableHeap drains d and compares the popped elements with m.
+func checkDurableHeap(t *testing.T, d *DurableHeap, m durableModel) {
+	t.Helper()
+
+	want := m.sorted()
+	if d.Len() != len(want) {
+		t.Fatalf("got %d elements, expect %d", d.Len(), len(want))
+	}
+	for _, e := range want {
+		id, key, err := d.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (durableElem{id, key}) != e {
+			t.Fatalf("got: %v, expect: %v", durableElem{id, key}, e)
+		}
+	}
+}
+
+// copyDir copies the files in src to a new temporary directory.
+func copyDir(t *testing.T, src string) string {
+	dst := t.TempDir()
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dst, e.Name()), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dst
+}
+
+func TestDurableHeap_Empty(t *testing.T) {
+	d := DurableHeap{}
+	if err := d.Init(t.TempDir(), 0); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	if !d.Empty() {
+		t.Fatal("d.Empty() should be true")
+	}
+	if _, _, err := d.DeleteMin(); err == nil {
+		t.Fatal("should report that d is empty")
+	}
+
+	id, _ := d.Insert(1)
+	if d.Empty() {
+		t.Fatal("d.Empty() should be false after insertion")
+	}
+	if _, err := d.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Delete(id); err == nil {
+		t.Fatal("should report that the element is not in the heap")
+	}
+	if err := d.DecreaseKey(id, 0); err == nil {
+		t.Fatal("should report that the element is not in the heap")
+	}
+}
+
+func TestDurableHeap_DecreaseKey(t *testing.T) {
+	d := DurableHeap{}
+	if err := d.Init(t.TempDir(), 0); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	id, _ := d.Insert(5)
+	if err := d.DecreaseKey(id, 10); err == nil {
+		t.Fatal("increase a key is not valid")
+	}
+}
+
+func TestDurableHeap_Recover(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	for _, every := range []int{0, 1, 16} {
+		dir := t.TempDir()
+		d := DurableHeap{}
+		if err := d.Init(dir, every); err != nil {
+			t.Fatal(err)
+		}
+		m := durableModel{}
+		randomDurableOps(t, rng, &d, m, 300)
+		if err := d.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// the IDs go on after recovery
+		if err := d.Init(dir, every); err != nil {
+			t.Fatal(err)
+		}
+		randomDurableOps(t, rng, &d, m, 300)
+		if err := d.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := d.Init(dir, every); err != nil {
+			t.Fatal(err)
+		}
+		checkDurableHeap(t, &d, m)
+		if err := d.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDurableHeap_TruncatedLog(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	dir := t.TempDir()
+	d := DurableHeap{}
+	if err := d.Init(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	states := randomDurableOps(t, rng, &d, durableModel{}, 100)
+
+	// crash without closing, and lose the end of the log at every byte
+	log, err := os.ReadFile(d.logPath(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 100*walRecordSize {
+		t.Fatalf("got: %d, expect: %d", len(log), 100*walRecordSize)
+	}
+
+	for size := 0; size <= len(log); size++ {
+		crashed := t.TempDir()
+		if err := os.WriteFile(filepath.Join(crashed, "wal-0.log"), log[:size], 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		r := DurableHeap{}
+		if err := r.Init(crashed, 0); err != nil {
+			t.Fatal(err)
+		}
+		m := maps.Clone(states[size/walRecordSize])
+		if r.Len() != len(m) {
+			t.Fatalf("size %d: got %d elements, expect %d", size, r.Len(), len(m))
+		}
+		for id, key := range m {
+			if got, err := r.Key(id); err != nil || got != key {
+				t.Fatalf("size %d: got: %d (%v), expect: %d", size, got, err, key)
+			}
+		}
+
+		// the torn record is cut off, so new records are not lost
+		if size%walRecordSize != 0 || size == len(log) {
+			id, err := r.Insert(-1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m[id] = -1
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Init(crashed, 0); err != nil {
+				t.Fatal(err)
+			}
+			checkDurableHeap(t, &r, m)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDurableHeap_CorruptedRecord(t *testing.T) {
+	dir := t.TempDir()
+	d := DurableHeap{}
+	if err := d.Init(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []int{5, 2, 7} {
+		if _, err := d.Insert(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	crashed := copyDir(t, dir)
+	path := filepath.Join(crashed, "wal-0.log")
+	log, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log[len(log)-walRecordSize+9] ^= 0xff
+	if err := os.WriteFile(path, log, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := DurableHeap{}
+	if err := r.Init(crashed, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	checkDurableHeap(t, &r, durableModel{1: 5, 2: 2})
+}
+
+func TestDurableHeap_CorruptedMiddleRecord(t *testing.T) {
+	dir := t.TempDir()
+	d := DurableHeap{}
+	if err := d.Init(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []int{5, 2, 7, 4, 1} {
+		if _, err := d.Insert(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "wal-0.log")
+	log, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log[walRecordSize+9] ^= 0x01
+	if err := os.WriteFile(path, log, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the records after the bad one are not dropped as if it were torn
+	r := DurableHeap{}
+	if err := r.Init(dir, 0); err == nil {
+		r.Close()
+		t.Fatal("should report that the log is corrupted")
+	}
+	if fi, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if fi.Size() != int64(len(log)) {
+		t.Fatalf("got: %d, expect: %d", fi.Size(), len(log))
+	}
+}
+
+func TestDurableHeap_CrashWithSnapshots(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	dir := t.TempDir()
+	d := DurableHeap{}
+	if err := d.Init(dir, 10); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	m := durableModel{}
+	history := []durableModel{{}}
+	for i := 0; i < 30; i++ {
+		states := randomDurableOps(t, rng, &d, m, rng.Intn(25))
+		history = append(history, states[1:]...)
+
+		// crash at this moment, losing a random part of the current log
+		crashed := copyDir(t, dir)
+		path := filepath.Join(crashed, filepath.Base(d.logPath(d.gen)))
+		log, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size := rng.Intn(len(log) + 1)
+		if err := os.WriteFile(path, log[:size], 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		// only the operations logged since the last snapshot can be lost
+		lost := d.ops - size/walRecordSize
+		want := history[len(history)-1-lost]
+
+		r := DurableHeap{}
+		if err := r.Init(crashed, 10); err != nil {
+			t.Fatal(err)
+		}
+		checkDurableHeap(t, &r, want)
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDurableHeap_CrashDuringSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	d := DurableHeap{}
+	if err := d.Init(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	for _, v := range []int{5, 2, 7} {
+		if _, err := d.Insert(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldLog, err := os.ReadFile(d.logPath(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+
+	// crash after the snapshot is renamed but before the old log is removed
+	crashed := copyDir(t, dir)
+	if err := os.WriteFile(filepath.Join(crashed, "wal-0.log"), oldLog, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(crashed, "wal-1.log")); err != nil {
+		t.Fatal(err)
+	}
+
+	r := DurableHeap{}
+	if err := r.Init(crashed, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := os.Stat(filepath.Join(crashed, "wal-0.log")); err == nil {
+		t.Fatal("the stale log should be removed")
+	}
+	checkDurableHeap(t, &r, durableModel{1: 5, 2: 2, 3: 7})
+
+	// a snapshot that is not renamed yet is ignored
+	if err := os.WriteFile(filepath.Join(crashed, "snapshot.tmp"), []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Init(crashed, 0); err != nil {
+		t.Fatal(err)
+	}
+	checkDurableHeap(t, &r, durableModel{})
+}
+
+func TestDurableHeap_SnapshotFailure(t *testing.T) {
+	dir := t.TempDir()
+	d := DurableHeap{}
+	if err := d.Init(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	if _, err := d.Insert(5); err != nil {
+		t.Fatal(err)
+	}
+	// the rename fails since the snapshot is a non-empty directory
+	blocker := filepath.Join(dir, durableSnapshot)
+	if err := os.MkdirAll(filepath.Join(blocker, "x"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Snapshot(); err == nil {
+		t.Fatal("should report that the snapshot cannot be renamed")
+	}
+	for _, name := range []string{"wal-1.log", "snapshot.tmp"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Fatalf("%s should be removed", name)
+		}
+	}
+
+	// d keeps logging to the old log
+	if _, err := d.Insert(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatal(err)
+	}
+
+	r := DurableHeap{}
+	if err := r.Init(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	checkDurableHeap(t, &r, durableModel{1: 5, 2: 2})
+}
+
+func TestDurableHeap_PeriodicSnapshotFailure(t *testing.T) {
+	dir := t.TempDir()
+	d := DurableHeap{}
+	if err := d.Init(dir, 2); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	// the rename fails since the snapshot is a non-empty directory
+	blocker := filepath.Join(dir, durableSnapshot)
+	if err := os.MkdirAll(filepath.Join(blocker, "x"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// the operations are done even though their snapshots fail
+	for _, v := range []int{5, 2, 7} {
+		if _, err := d.Insert(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := d.DeleteMin(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Err() == nil {
+		t.Fatal("should report that the snapshot failed")
+	}
+	if d.Len() != 2 {
+		t.Fatalf("got: %d, expect: %d", d.Len(), 2)
+	}
+
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Insert(4); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := DurableHeap{}
+	if err := r.Init(dir, 2); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	checkDurableHeap(t, &r, durableModel{1: 5, 3: 7, 4: 4})
+}
@@ -0,0 +1,326 @@
+package priorityqueue
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+)
+
+// externalMaxRuns is the number of runs at a level that ExternalPQ
+// merges into a single run at the next level.
+const externalMaxRuns = 16
+
+// ExternalPQ is an external-memory priority queue for more elements than
+// the memory can hold. At most limit elements are kept in an in-memory
+// heap, and whenever it is full, its elements are written to a file in
+// the given directory as a sorted run at level 0. Once there are
+// externalMaxRuns runs at a level, they are merged into a single run at the
+// next level, so every element is rewritten once per level, and there are
+// O(log(N/limit)) levels for N elements. DeleteMin merges the runs lazily,
+// reading only the head of each run into memory.
+//
+// The elements are stored without handles, so the DataNode returned by
+// Insert and MinNode only carries the key.
+//
+// It must be initialized by Init before use, and closed by Close to
+// remove its files.
+type ExternalPQ struct {
+	dir   string
+	limit int
+	n     int
+
+	mem    nodeHeap[int]
+	runs   nodeHeap[*extRun] // ordered by the heads of the runs
+	levels []int             // number of runs at each level
+
+	err error
+}
+
+// extKey is the DataNode of an element in an ExternalPQ.
+type extKey int
+
+func (k extKey) Data() int {
+	return int(k)
+}
+
+// extRun is a sorted run in a file, read from its beginning to the end.
+type extRun struct {
+	file  *os.File
+	r     *bufio.Reader
+	head  int
+	n     int // number of the elements left, including head
+	level int
+}
+
+// next advances r to the next element,
+// and removes the file after the last element is consumed.
+func (r *extRun) next() error {
+	r.n--
+	if r.n == 0 {
+		return r.remove()
+	}
+
+	var buf [8]byte
+	if _, err := io.ReadFull(r.r, buf[:]); err != nil {
+		return err
+	}
+	r.head = int(binary.LittleEndian.Uint64(buf[:]))
+	return nil
+}
+
+func (r *extRun) remove() error {
+	return errors.Join(r.file.Close(), os.Remove(r.file.Name()))
+}
+
+// Init empties e and sets it up to spill its elements to files in dir,
+// keeping at most limit elements in memory, error if limit is not positive
+// or dir is not a directory. A used e must be closed before Init.
+func (e *ExternalPQ) Init(dir string, limit int) error {
+	if limit <= 0 {
+		return fmt.Errorf("limit %d is not positive", limit)
+	}
+	if fi, err := os.Stat(dir); err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	*e = ExternalPQ{dir: dir, limit: limit}
+	e.mem.less = func(x, y int) bool { return x < y }
+	e.runs.less = func(x, y *extRun) bool { return x.head < y.head }
+	return nil
+}
+
+// Err returns the first I/O error met by e. Once an error happened,
+// Min and DeleteMin keep returning it, and Insert discards its elements,
+// since elements may have been lost.
+func (e *ExternalPQ) Err() error {
+	return e.err
+}
+
+// Min peeks and returns the minimum of the queue,
+// error if the queue is empty or an I/O error happened.
+func (e *ExternalPQ) Min() (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	if e.Empty() {
+		return 0, fmt.Errorf("queue is empty")
+	}
+
+	if e.runs.len() == 0 || e.mem.len() > 0 && e.mem.nodes[0] <= e.runs.nodes[0].head {
+		return e.mem.nodes[0], nil
+	}
+	return e.runs.nodes[0].head, nil
+}
+
+// MinNode peeks and returns the minimum of the queue as a DataNode,
+// which only carries the key.
+func (e *ExternalPQ) MinNode() (DataNode, error) {
+	x, err := e.Min()
+	if err != nil {
+		return nil, err
+	}
+	return extKey(x), nil
+}
+
+// Empty returns whether the queue is empty or not.
+func (e *ExternalPQ) Empty() bool {
+	return e.n == 0
+}
+
+// Insert x into the ExternalPQ and return it as a DataNode.
+// The in-memory heap is written to a new run when it is full,
+// and an I/O error of that is reported by Err.
+//
+// Amortized cost is O(lg m + log(N/m)) with O(log(N/m)/B) I/Os, where m is
+// the limit of the in-memory heap, N is the number of elements, and B is
+// the number of elements in a block.
+func (e *ExternalPQ) Insert(x int) DataNode {
+	if e.err != nil {
+		return extKey(x)
+	}
+
+	e.mem.push(x)
+	e.n++
+	if e.mem.len() > e.limit {
+		e.err = e.spill()
+	}
+	return extKey(x)
+}
+
+// DeleteMin pops the minimum from the ExternalPQ then returns it,
+// error if the queue is empty or an I/O error happened.
+//
+// Amortized cost is O(lg m + lg r) with O(1/B) I/Os, where r is the number
+// of runs, which is less than externalMaxRuns times the number of levels.
+func (e *ExternalPQ) DeleteMin() (int, error) {
+	x, err := e.Min()
+	if err != nil {
+		return 0, err
+	}
+	e.n--
+
+	if e.mem.len() > 0 && e.mem.nodes[0] == x {
+		e.mem.pop()
+		return x, nil
+	}
+
+	r := e.runs.pop()
+	err = r.next()
+	if err == nil && r.n > 0 {
+		e.runs.push(r)
+		return x, nil
+	}
+
+	// r is used up, whose file is removed by next, or it failed
+	e.levels[r.level]--
+	if err != nil {
+		if r.n > 0 {
+			err = errors.Join(err, r.remove())
+		}
+		e.err = err
+	}
+	return x, nil
+}
+
+// Close removes all files of e and empties it,
+// and returns the first error met by e if any.
+func (e *ExternalPQ) Close() error {
+	err := e.err
+	for _, r := range e.runs.nodes {
+		err = errors.Join(err, r.remove())
+	}
+
+	e.mem.nodes, e.runs.nodes, e.levels = nil, nil, nil
+	e.n, e.err = 0, nil
+	return err
+}
+
+// spill writes the in-memory heap to a new run at level 0,
+// and merges the runs of every level that is full.
+func (e *ExternalPQ) spill() error {
+	xs := e.mem.nodes
+	slices.Sort(xs)
+
+	r, err := e.writeRun(len(xs), func(yield func(int) bool) {
+		for _, x := range xs {
+			if !yield(x) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	e.mem.nodes = xs[:0]
+	e.addRun(r)
+
+	for l := 0; l < len(e.levels) && e.levels[l] >= externalMaxRuns; l++ {
+		if err := e.mergeLevel(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ExternalPQ) addRun(r *extRun) {
+	for len(e.levels) <= r.level {
+		e.levels = append(e.levels, 0)
+	}
+	e.levels[r.level]++
+	e.runs.push(r)
+}
+
+// mergeLevel merges the remaining elements of the runs at level l
+// into a single run at level l+1. If it fails, the files of those runs
+// are removed, since some of their elements have been consumed.
+func (e *ExternalPQ) mergeLevel(l int) error {
+	merging := nodeHeap[*extRun]{less: e.runs.less}
+	rest := e.runs.nodes[:0]
+	n := 0
+	for _, r := range e.runs.nodes {
+		if r.level == l {
+			merging.nodes = append(merging.nodes, r)
+			n += r.n
+		} else {
+			rest = append(rest, r)
+		}
+	}
+	e.runs.nodes = rest
+	e.runs.heapify()
+	e.levels[l] = 0
+	merging.heapify()
+
+	var readErr error
+	merged, err := e.writeRun(n, func(yield func(int) bool) {
+		for merging.len() > 0 {
+			r := merging.nodes[0]
+			if !yield(r.head) {
+				return
+			}
+			readErr = r.next()
+			if r.n == 0 {
+				merging.pop()
+			} else if readErr == nil {
+				merging.down(0)
+			}
+			if readErr != nil {
+				return
+			}
+		}
+	})
+	if err = errors.Join(readErr, err); err != nil {
+		if merged != nil {
+			err = errors.Join(err, merged.remove())
+		}
+		for _, r := range merging.nodes {
+			err = errors.Join(err, r.remove())
+		}
+		return err
+	}
+
+	merged.level = l + 1
+	e.addRun(merged)
+	return nil
+}
+
+// writeRun writes n elements from seq to a new file, and opens it as a run.
+func (e *ExternalPQ) writeRun(n int, seq iter.Seq[int]) (*extRun, error) {
+	file, err := os.CreateTemp(e.dir, "externalpq-*.run")
+	if err != nil {
+		return nil, err
+	}
+	r := &extRun{file: file, n: n}
+
+	w := bufio.NewWriterSize(file, 1<<16)
+	var buf [8]byte
+	seq(func(x int) bool {
+		binary.LittleEndian.PutUint64(buf[:], uint64(x))
+		_, err = w.Write(buf[:])
+		return err == nil
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return nil, errors.Join(err, r.remove())
+	}
+
+	// read the first element as the head
+	r.r = bufio.NewReaderSize(file, 1<<16)
+	r.n++
+	if err := r.next(); err != nil {
+		return nil, errors.Join(err, r.remove())
+	}
+	return r, nil
+}
@@ -0,0 +1,241 @@
+package priorityqueue
+
+import (
+	"math/rand"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+var _ PriorityQueue = (*ExternalPQ)(nil)
+
+func TestExternalPQ_Init(t *testing.T) {
+	e := ExternalPQ{}
+	if err := e.Init(t.TempDir(), 0); err == nil {
+		t.Fatal("should report that the limit is not positive")
+	}
+
+	file, err := os.CreateTemp(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := e.Init(file.Name(), 16); err == nil {
+		t.Fatal("should report that the path is not a directory")
+	}
+}
+
+func TestExternalPQ_Empty(t *testing.T) {
+	e := ExternalPQ{}
+	if err := e.Init(t.TempDir(), 1); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	if !e.Empty() {
+		t.Fatal("e.Empty() should be true")
+	}
+
+	e.Insert(1)
+	e.Insert(2)
+	if e.Empty() {
+		t.Fatal("e.Empty() should be false after insertion")
+	}
+
+	_, _ = e.DeleteMin()
+	_, _ = e.DeleteMin()
+	if !e.Empty() {
+		t.Fatal("e.Empty() should be true after delete all elements")
+	}
+
+	if _, err := e.DeleteMin(); err == nil {
+		t.Fatal("should report that e is empty")
+	}
+	if _, err := e.Min(); err == nil {
+		t.Fatal("should report that e is empty")
+	}
+}
+
+func TestExternalPQ_DeleteMin(t *testing.T) {
+	e := ExternalPQ{}
+	if err := e.Init(t.TempDir(), 4); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		e.Insert(v)
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		h, err := e.MinNode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Data() != ans {
+			t.Fatalf("got: %d, expect: %d", h.Data(), ans)
+		}
+
+		p, err := e.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+}
+
+func TestExternalPQ_Random(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	dir := t.TempDir()
+	e := ExternalPQ{}
+	if err := e.Init(dir, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	// enough runs are written to be merged several times
+	var keys []int
+	for i := 0; i < 8192; i++ {
+		if len(keys) == 0 || rng.Intn(4) > 0 {
+			x := rng.Intn(1<<16) - 1<<15
+			e.Insert(x)
+			j, _ := slices.BinarySearch(keys, x)
+			keys = slices.Insert(keys, j, x)
+			continue
+		}
+
+		p, err := e.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != keys[0] {
+			t.Fatalf("got: %d, expect: %d", p, keys[0])
+		}
+		keys = keys[1:]
+	}
+	if err := e.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != e.runs.len() {
+		t.Fatalf("got %d files, expect %d", len(files), e.runs.len())
+	}
+	total := 0
+	for l, k := range e.levels {
+		if k >= externalMaxRuns {
+			t.Fatalf("level %d has %d runs, expect less than %d", l, k, externalMaxRuns)
+		}
+		total += k
+	}
+	if len(e.levels) < 2 || total != e.runs.len() {
+		t.Fatalf("got %d runs in %d levels, expect %d runs in 2 or more levels",
+			total, len(e.levels), e.runs.len())
+	}
+
+	for _, ans := range keys[:len(keys)/2] {
+		p, err := e.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Fatalf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("%d files are left after Close", len(files))
+	}
+	if !e.Empty() {
+		t.Fatal("e should be empty after Close")
+	}
+}
+
+// truncateRun truncates the file of the only run of e at level l,
+// so that reading it fails once its buffered elements are consumed.
+func truncateRun(t *testing.T, e *ExternalPQ, l int) {
+	for _, r := range e.runs.nodes {
+		if r.level == l {
+			if err := os.Truncate(r.file.Name(), 0); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+	}
+	t.Fatalf("no run at level %d", l)
+}
+
+func TestExternalPQ_DeleteMinError(t *testing.T) {
+	dir := t.TempDir()
+	e := ExternalPQ{}
+	// a run larger than the read buffer
+	const limit = 1 << 14
+	if err := e.Init(dir, limit); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	for i := 0; i <= limit; i++ {
+		e.Insert(i)
+	}
+	truncateRun(t, &e, 0)
+
+	for i := 0; e.Err() == nil; i++ {
+		if p, err := e.DeleteMin(); err != nil {
+			t.Fatal(err)
+		} else if p != i {
+			t.Fatalf("got: %d, expect: %d", p, i)
+		}
+	}
+	if _, err := e.DeleteMin(); err == nil {
+		t.Fatal("should report the read error")
+	}
+
+	// the failed run is removed at once, and no more elements are kept
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("%d files are left after the error", len(files))
+	}
+	e.Insert(0)
+	if e.mem.len() != 0 {
+		t.Fatalf("got: %d, expect: %d", e.mem.len(), 0)
+	}
+}
+
+func TestExternalPQ_MergeError(t *testing.T) {
+	dir := t.TempDir()
+	e := ExternalPQ{}
+	const limit = 1 << 14
+	if err := e.Init(dir, limit); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// the runs are merged by the spill of the last one
+	for i := 0; i < externalMaxRuns*(limit+1)-1; i++ {
+		e.Insert(i)
+	}
+	if e.levels[0] != externalMaxRuns-1 {
+		t.Fatalf("got: %d, expect: %d", e.levels[0], externalMaxRuns-1)
+	}
+	truncateRun(t, &e, 0)
+	e.Insert(0)
+	if e.Err() == nil {
+		t.Fatal("should report the read error")
+	}
+
+	// neither the merged run nor the runs being merged are left
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("%d files are left after the error", len(files))
+	}
+}
@@ -2,8 +2,11 @@ package priorityqueue
 
 type fHeapNode struct {
 	data          int
+	seq           uint64
 	degree        int
 	lostChild     bool
+	removed       bool
+	escaped       bool // handed out as a handle, so it is never recycled
 	prev, next    *fHeapNode
 	child, parent *fHeapNode
 }
@@ -12,20 +15,28 @@ func (n *fHeapNode) Data() int {
 	return n.data
 }
 
-func (n *fHeapNode) Next() BiDirTreeNode {
+func (n *fHeapNode) valid() bool {
+	return n != nil && !n.removed
+}
+
+func (n *fHeapNode) Seq() uint64 {
+	return n.seq
+}
+
+func (n *fHeapNode) Next() *fHeapNode {
 	return n.next
 }
 
-func (n *fHeapNode) Prev() BiDirTreeNode {
+func (n *fHeapNode) Prev() *fHeapNode {
 	return n.prev
 }
 
-func (n *fHeapNode) SetNext(other BiDirTreeNode) {
-	n.next = other.(*fHeapNode)
+func (n *fHeapNode) SetNext(other *fHeapNode) {
+	n.next = other
 }
 
-func (n *fHeapNode) SetPrev(other BiDirTreeNode) {
-	n.prev = other.(*fHeapNode)
+func (n *fHeapNode) SetPrev(other *fHeapNode) {
+	n.prev = other
 }
 
 func (n *fHeapNode) AddSibling(s *fHeapNode) {
@@ -35,26 +46,22 @@ func (n *fHeapNode) AddSibling(s *fHeapNode) {
 	n.prev = s
 }
 
-func (n *fHeapNode) AddChild(ch BiDirTreeNode) {
-	if isNilPtr(ch) {
+func (n *fHeapNode) AddChild(ch *fHeapNode) {
+	if ch == nil {
 		return
 	}
 
-	if ch, ok := ch.(*fHeapNode); ok {
-		ch.parent = n
-		ch.lostChild = false
+	ch.parent = n
+	ch.lostChild = false
 
-		if n.child == nil {
-			n.child, ch.next, ch.prev = ch, ch, ch
-			n.degree = 1
-			return
-		}
-
-		n.child.AddSibling(ch)
-		n.degree++
-	} else {
-		panic("the type of ch is incorrect")
+	if n.child == nil {
+		n.child, ch.next, ch.prev = ch, ch, ch
+		n.degree = 1
+		return
 	}
+
+	n.child.AddSibling(ch)
+	n.degree++
 }
 
 func (n *fHeapNode) pruneParentFromChildren() {
@@ -2,7 +2,10 @@ package priorityqueue
 
 import (
 	"fmt"
+	"iter"
 	"math"
+
+	"github.com/25349023/datastruct/internal/pool"
 )
 
 var logPhi = math.Log(math.Phi)
@@ -10,8 +13,41 @@ var logPhi = math.Log(math.Phi)
 // FibonacciHeap implementation that is introduced in
 // 'Fundamentals of Data Structures in C'
 type FibonacciHeap struct {
-	min *fHeapNode
-	n   int
+	min    *fHeapNode
+	n      int
+	stable bool
+	pool   *pool.Pool[fHeapNode]
+	trees  []*fHeapNode // buffer of the consolidation
+}
+
+// SetStable enables or disables the stable mode of f, error if f is not empty.
+//
+// In stable mode, elements with equal keys leave the heap in the order they
+// were inserted, even if they come from different heaps melded together.
+func (f *FibonacciHeap) SetStable(stable bool) error {
+	if !f.Empty() {
+		return fmt.Errorf("cannot change the mode of non-empty heap")
+	}
+	f.stable = stable
+	return nil
+}
+
+func (f *FibonacciHeap) less(x, y *fHeapNode) bool {
+	return lessNode(x, y, f.stable)
+}
+
+// SetPooled enables or disables the pooled mode of f.
+//
+// In pooled mode, nodes are allocated in blocks, and the nodes inserted by
+// Push are recycled once they are popped. Nodes that have ever been handed
+// out as handles are never recycled, so a stale handle cannot refer to
+// another element.
+func (f *FibonacciHeap) SetPooled(pooled bool) {
+	if !pooled {
+		f.pool = nil
+	} else if f.pool == nil {
+		f.pool = &pool.Pool[fHeapNode]{}
+	}
 }
 
 // Min peeks and returns the minimum of the heap.
@@ -22,6 +58,15 @@ func (f *FibonacciHeap) Min() (int, error) {
 	return f.min.data, nil
 }
 
+// MinNode peeks and returns the node of the minimum of the heap.
+func (f *FibonacciHeap) MinNode() (DataNode, error) {
+	if f.Empty() {
+		return nil, fmt.Errorf("heap is empty")
+	}
+	f.min.escaped = true
+	return f.min, nil
+}
+
 // Empty returns whether the heap is empty or not.
 func (f *FibonacciHeap) Empty() bool {
 	return f.min == nil
@@ -31,21 +76,258 @@ func (f *FibonacciHeap) Empty() bool {
 //
 // Amortized cost is O(1).
 func (f *FibonacciHeap) Insert(x int) DataNode {
-	node := &fHeapNode{data: x}
+	node := f.newNode(x)
+	node.escaped = true
+	f.insertNode(node)
+	return node
+}
+
+// Push inserts x into the FibonacciHeap like Insert, but returns no handle,
+// so that the node can be recycled after it is popped in pooled mode.
+//
+// Amortized cost is O(1).
+func (f *FibonacciHeap) Push(x int) {
+	f.insertNode(f.newNode(x))
+}
 
+func (f *FibonacciHeap) newNode(x int) *fHeapNode {
+	var node *fHeapNode
+	if f.pool != nil {
+		node = f.pool.Get()
+		node.data = x
+	} else {
+		node = &fHeapNode{data: x}
+	}
+
+	if f.stable {
+		node.seq = insertSeq.Add(1)
+	}
+	return node
+}
+
+// release recycles the popped node x if it has never been handed out.
+func (f *FibonacciHeap) release(x *fHeapNode) {
+	if f.pool != nil && !x.escaped {
+		f.pool.Put(x)
+	}
+}
+
+// insertNode adds node into the root list as a single-node tree.
+func (f *FibonacciHeap) insertNode(node *fHeapNode) {
 	defer func() { f.n++ }()
 
 	if f.min == nil {
 		f.min, node.prev, node.next = node, node, node
-		return node
+		return
 	}
 
 	f.min.AddSibling(node)
 
-	if x < f.min.data {
+	if f.less(node, f.min) {
 		f.min = node
 	}
-	return node
+}
+
+// InsertMany inserts all elements of xs into the FibonacciHeap
+// and returns the inserted nodes in the order of xs.
+// The new nodes are allocated in one block
+// and spliced into the root list at once.
+//
+// Amortized cost is O(k), where k is len(xs).
+func (f *FibonacciHeap) InsertMany(xs []int) []DataNode {
+	nodes := make([]DataNode, len(xs))
+	block := make([]fHeapNode, len(xs))
+	var list, min *fHeapNode
+	for i, x := range xs {
+		node := &block[i]
+		node.data, node.escaped = x, true
+		if f.stable {
+			node.seq = insertSeq.Add(1)
+		}
+		nodes[i] = node
+
+		if list == nil {
+			list, min, node.prev, node.next = node, node, node, node
+			continue
+		}
+		list.AddSibling(node)
+		if f.less(node, min) {
+			min = node
+		}
+	}
+
+	if list == nil {
+		return nodes
+	}
+	if f.min == nil {
+		f.min = min
+	} else {
+		mergeLists(f.min, list)
+		if f.less(min, f.min) {
+			f.min = min
+		}
+	}
+
+	f.n += len(xs)
+	return nodes
+}
+
+// DeleteMinN pops the k smallest elements from the FibonacciHeap
+// and returns them in ascending order.
+// All elements are returned if the heap holds fewer than k elements.
+//
+// Amortized cost is O(k lg n).
+func (f *FibonacciHeap) DeleteMinN(k int) []int {
+	var res []int
+	f.extract(k, math.MaxInt, func(x int) bool {
+		res = append(res, x)
+		return true
+	})
+	return res
+}
+
+// ExtractUpTo pops all elements whose keys are at most limit from the
+// FibonacciHeap and returns them in ascending order.
+//
+// Amortized cost is O(k lg n), where k is the number of popped elements.
+func (f *FibonacciHeap) ExtractUpTo(limit int) []int {
+	var res []int
+	f.extract(f.n, limit, func(x int) bool {
+		res = append(res, x)
+		return true
+	})
+	return res
+}
+
+// ExtractUpToSeq returns an iterator that pops the elements whose keys are
+// at most limit from the FibonacciHeap in ascending order. Elements that are not
+// reached because the iteration stops early stay in the heap.
+// The heap must not be modified until the iteration ends.
+func (f *FibonacciHeap) ExtractUpToSeq(limit int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		f.extract(f.n, limit, yield)
+	}
+}
+
+// extract pops at most k elements whose keys are at most limit in ascending
+// order, and passes them to yield until it returns false.
+//
+// The trees are consolidated first, then the nodes are taken from the top of
+// the trees in the order of their keys, and the remaining trees are
+// consolidated only once afterwards, instead of once per popped element.
+func (f *FibonacciHeap) extract(k, limit int, yield func(int) bool) {
+	if f.Empty() || k <= 0 || f.min.data > limit {
+		return
+	}
+
+	f.relink(f.mergeSameDegreeTrees())
+
+	// the candidates are the roots and the children of popped nodes,
+	// and those greater than limit are kept in rest
+	cand := nodeHeap[*fHeapNode]{less: f.less}
+	var rest []*fHeapNode
+	add := func(x *fHeapNode) {
+		if x.data <= limit {
+			cand.nodes = append(cand.nodes, x)
+		} else {
+			rest = append(rest, x)
+		}
+	}
+	for x := f.min; ; x = x.next {
+		add(x)
+		if x.next == f.min {
+			break
+		}
+	}
+	cand.heapify()
+
+	for ; k > 0 && cand.len() > 0; k-- {
+		x := cand.pop()
+		x.removed = true
+		f.n--
+		data := x.data
+
+		for c := x.child; c != nil; c = c.next {
+			c.parent = nil
+			if c.data <= limit {
+				cand.push(c)
+			} else {
+				rest = append(rest, c)
+			}
+			if c.next == x.child {
+				break
+			}
+		}
+
+		f.release(x)
+		if !yield(data) {
+			break
+		}
+	}
+
+	// the remaining candidates are the roots of the remaining trees
+	f.relink(append(rest, cand.nodes...))
+	if f.min != nil {
+		f.relink(f.mergeSameDegreeTrees())
+	}
+}
+
+// CountUpTo returns the number of elements in the FibonacciHeap
+// whose keys are at most limit.
+//
+// Actual cost is O(k lg n + r), where k is the result
+// and r is the number of trees.
+func (f *FibonacciHeap) CountUpTo(limit int) int {
+	count := 0
+	f.visitUpTo(limit, func(*fHeapNode) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// VisitUpTo calls visit on the nodes of the FibonacciHeap whose keys are at most
+// limit, in no particular order, until visit returns false.
+// Only the subtrees whose roots qualify are walked, and nothing is removed.
+// The heap must not be modified during the visit.
+//
+// Actual cost is O(k lg n + r), where k is the number of visited nodes
+// and r is the number of trees.
+func (f *FibonacciHeap) VisitUpTo(limit int, visit func(DataNode) bool) {
+	f.visitUpTo(limit, func(x *fHeapNode) bool {
+		x.escaped = true
+		return visit(x)
+	})
+}
+
+// visitUpTo is like VisitUpTo, but does not hand the nodes out,
+// so they can still be recycled in pooled mode.
+func (f *FibonacciHeap) visitUpTo(limit int, visit func(*fHeapNode) bool) {
+	if f.Empty() {
+		return
+	}
+
+	var stack []*fHeapNode
+	push := func(list *fHeapNode) {
+		for x := list; x != nil; x = x.next {
+			if x.data <= limit {
+				stack = append(stack, x)
+			}
+			if x.next == list {
+				break
+			}
+		}
+	}
+
+	push(f.min)
+	for len(stack) > 0 {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !visit(x) {
+			return
+		}
+		push(x.child)
+	}
 }
 
 // DeleteMin pops the minimum from the FibonacciHeap then returns it,
@@ -60,6 +342,8 @@ func (f *FibonacciHeap) DeleteMin() (int, error) {
 	defer func() { f.n-- }()
 
 	minValue := f.min.data
+	f.min.removed = true
+	defer f.release(f.min)
 	f.min.pruneParentFromChildren()
 
 	// Step 1: delete min node
@@ -93,12 +377,16 @@ func (f *FibonacciHeap) DeleteMin() (int, error) {
 
 func (f *FibonacciHeap) mergeSameDegreeTrees() []*fHeapNode {
 	maxDegree := int(math.Log(float64(f.n))/logPhi) + 1
-	trees := make([]*fHeapNode, maxDegree)
+	if cap(f.trees) < maxDegree {
+		f.trees = make([]*fHeapNode, maxDegree)
+	}
+	trees := f.trees[:maxDegree]
+	clear(trees)
 
 	for p, next := f.min, f.min.next; ; p, next = next, next.next {
 		d := p.degree
 		for ; trees[d] != nil; d++ {
-			p = joinMinTrees(p, trees[d]).(*fHeapNode)
+			p = joinMinTrees(p, trees[d], f.stable)
 			trees[d] = nil
 		}
 		trees[d] = p
@@ -120,7 +408,7 @@ func (f *FibonacciHeap) relink(trees []*fHeapNode) {
 			f.min, node.next, node.prev = node, node, node
 		} else {
 			f.min.AddSibling(node)
-			if node.data < f.min.data {
+			if f.less(node, f.min) {
 				f.min = node
 			}
 		}
@@ -128,11 +416,15 @@ func (f *FibonacciHeap) relink(trees []*fHeapNode) {
 }
 
 // Meld two FibonacciHeap and leave other empty,
-// error if the underlying type of other is not FibonacciHeap.
+// error if the underlying type of other is not FibonacciHeap
+// or only one of them is in stable mode.
 //
 // Amortized cost is O(1).
 func (f *FibonacciHeap) Meld(other MeldablePQ) error {
 	if other, ok := other.(*FibonacciHeap); ok {
+		if other.stable != f.stable {
+			return fmt.Errorf("cannot meld stable and non-stable heaps")
+		}
 		if other.Empty() {
 			return nil
 		}
@@ -144,7 +436,7 @@ func (f *FibonacciHeap) Meld(other MeldablePQ) error {
 
 		mergeLists(f.min, other.min)
 
-		if other.min.data < f.min.data {
+		if f.less(other.min, f.min) {
 			f.min = other.min
 		}
 		f.n += other.n
@@ -156,8 +448,67 @@ func (f *FibonacciHeap) Meld(other MeldablePQ) error {
 	return fmt.Errorf("cannot meld with non binomial heap")
 }
 
-// Delete the specified arbitrary node in the FibonacciHeap f,
-// error if f is empty or the target's type is incorrect.
+// Clone returns a deep copy of f, which shares no nodes with f.
+//
+// Actual cost is O(n).
+func (f *FibonacciHeap) Clone() *FibonacciHeap {
+	c, _ := f.clone(false)
+	return c
+}
+
+// CloneWithHandles is like Clone, but also returns a mapping from the
+// handles of f to the corresponding handles of the copy.
+//
+// Actual cost is O(n).
+func (f *FibonacciHeap) CloneWithHandles() (*FibonacciHeap, map[DataNode]DataNode) {
+	return f.clone(true)
+}
+
+func (f *FibonacciHeap) clone(withHandles bool) (*FibonacciHeap, map[DataNode]DataNode) {
+	c := &FibonacciHeap{n: f.n, stable: f.stable}
+	if f.pool != nil {
+		c.pool = &pool.Pool[fHeapNode]{}
+	}
+	var handles map[DataNode]DataNode
+	if withHandles {
+		handles = make(map[DataNode]DataNode, f.n)
+	}
+
+	// copyList copies the circular list starting from list and all their
+	// subtrees, and returns the copy of list
+	var copyList func(list, parent *fHeapNode) *fHeapNode
+	copyList = func(list, parent *fHeapNode) *fHeapNode {
+		var head *fHeapNode
+		for x := list; ; x = x.next {
+			y := &fHeapNode{data: x.data, seq: x.seq, degree: x.degree, lostChild: x.lostChild, parent: parent}
+			if head == nil {
+				head, y.prev, y.next = y, y, y
+			} else {
+				head.AddSibling(y)
+			}
+			if x.child != nil {
+				y.child = copyList(x.child, y)
+			}
+			if withHandles {
+				y.escaped = true
+				handles[x] = y
+			}
+
+			if x.next == list {
+				break
+			}
+		}
+		return head
+	}
+
+	if f.min != nil {
+		c.min = copyList(f.min, nil)
+	}
+	return c, handles
+}
+
+// Delete the specified arbitrary node in the FibonacciHeap f, error if f is
+// empty, the target is no longer in f or the target's type is incorrect.
 //
 // Amortized cost is O(lg n).
 func (f *FibonacciHeap) Delete(target DataNode) (int, error) {
@@ -166,11 +517,15 @@ func (f *FibonacciHeap) Delete(target DataNode) (int, error) {
 	}
 
 	if target, ok := target.(*fHeapNode); ok {
+		if !target.valid() {
+			return 0, fmt.Errorf("handle is no longer in the queue")
+		}
 		if target == f.min {
 			return f.DeleteMin()
 		}
 
 		popValue := target.data
+		target.removed = true
 
 		f.cutChild(target, true)
 		f.n--
@@ -189,25 +544,29 @@ func (f *FibonacciHeap) Delete(target DataNode) (int, error) {
 	return 0, fmt.Errorf("incorrect type of target")
 }
 
-// DecreaseKey decrease the key of the specified node in f,
-// error if key is greater than original key or the target's type is incorrect.
+// DecreaseKey decrease the key of the specified node in f, error if key is
+// greater than original key, the target is no longer in f or the target's
+// type is incorrect.
 //
 // Amortized cost is O(1).
 func (f *FibonacciHeap) DecreaseKey(target DataNode, key int) error {
 	if target, ok := target.(*fHeapNode); ok {
+		if !target.valid() {
+			return fmt.Errorf("handle is no longer in the queue")
+		}
 		if target.data < key {
 			return fmt.Errorf("new key is greater than original key")
 		}
 
 		target.data = key
 
-		if p := target.parent; p != nil && target.data < p.data {
+		if p := target.parent; p != nil && f.less(target, p) {
 			f.cutChild(target, false)
 			mergeLists(f.min, target)
 			f.cascadingCut(p)
 		}
 
-		if target.data < f.min.data {
+		if f.less(target, f.min) {
 			f.min = target
 		}
 
@@ -217,6 +576,69 @@ func (f *FibonacciHeap) DecreaseKey(target DataNode, key int) error {
 	return fmt.Errorf("incorrect type of target")
 }
 
+// IncreaseKey increase the key of the specified node in f, error if key is
+// less than original key, the target is no longer in f or the target's type
+// is incorrect.
+// The target stays valid as a handle of the element.
+//
+// Amortized cost is O(lg n).
+func (f *FibonacciHeap) IncreaseKey(target DataNode, key int) error {
+	if target, ok := target.(*fHeapNode); ok {
+		if !target.valid() {
+			return fmt.Errorf("handle is no longer in the queue")
+		}
+		if key < target.data {
+			return fmt.Errorf("new key is less than original key")
+		}
+
+		if target == f.min {
+			_, _ = f.DeleteMin()
+			target.data, target.degree, target.lostChild = key, 0, false
+			target.child, target.parent, target.removed = nil, nil, false
+			f.insertNode(target)
+			return nil
+		}
+
+		// children may become smaller than target, so move them to the root list
+		target.pruneParentFromChildren()
+		if target.child != nil {
+			mergeLists(f.min, target.child)
+			target.child, target.degree = nil, 0
+		}
+		target.data = key
+
+		if p := target.parent; p != nil {
+			f.cutChild(target, false)
+			mergeLists(f.min, target)
+			f.cascadingCut(p)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
+// UpdateKey sets the key of the specified node in f to key,
+// by DecreaseKey or IncreaseKey depending on the original key,
+// error if the target is no longer in f or the target's type is incorrect.
+func (f *FibonacciHeap) UpdateKey(target DataNode, key int) error {
+	if target, ok := target.(*fHeapNode); ok {
+		if !target.valid() {
+			return fmt.Errorf("handle is no longer in the queue")
+		}
+		switch {
+		case key < target.data:
+			return f.DecreaseKey(target, key)
+		case key > target.data:
+			return f.IncreaseKey(target, key)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
 func (f *FibonacciHeap) cutChild(target *fHeapNode, delete bool) {
 	f.removeFromParent(target)
 
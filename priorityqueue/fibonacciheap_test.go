@@ -2,6 +2,7 @@ package priorityqueue
 
 import (
 	"math/rand"
+	"slices"
 	"testing"
 	"time"
 )
@@ -244,6 +245,35 @@ func TestFibonacciHeap_Delete2(t *testing.T) {
 	}
 }
 
+func TestFibonacciHeap_StaleHandle(t *testing.T) {
+	f := FibonacciHeap{}
+	hd := f.Insert(3)
+	f.Insert(5)
+	f.Insert(4)
+	if _, err := f.Delete(hd); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.Delete(hd); err == nil {
+		t.Fatal("should report that the handle is no longer in the heap")
+	}
+	if f.n != 2 {
+		t.Fatalf("got: %d, expect: %d", f.n, 2)
+	}
+
+	// a handle popped by DeleteMin must not come back as the minimum
+	popped := f.Insert(1)
+	if p, _ := f.DeleteMin(); p != 1 {
+		t.Fatalf("got: %d, expect: 1", p)
+	}
+	if err := f.DecreaseKey(popped, 0); err == nil {
+		t.Fatal("should report that the handle is no longer in the heap")
+	}
+	if p, _ := f.Min(); p != 4 {
+		t.Fatalf("got: %d, expect: 4", p)
+	}
+}
+
 func TestFibonacciHeap_RandomDelete(t *testing.T) {
 	seed := time.Now().UTC().UnixNano()
 	t.Logf("Random seed: %d", seed)
@@ -391,3 +421,715 @@ func TestFibonacciHeap_RandomDecreaseKey(t *testing.T) {
 		t.Fatal("f should be empty: ", f.min.data)
 	}
 }
+
+func TestFibonacciHeap_Stable(t *testing.T) {
+	f := FibonacciHeap{}
+	if err := f.SetStable(true); err != nil {
+		t.Fatal(err)
+	}
+
+	var hd []DataNode
+	for i := 0; i < 100; i++ {
+		hd = append(hd, f.Insert(i%4))
+	}
+
+	for k := 0; k < 4; k++ {
+		for i := k; i < len(hd); i += 4 {
+			if f.min != hd[i] {
+				t.Fatalf("element #%d should be the minimum", i)
+			}
+			if p, _ := f.DeleteMin(); p != k {
+				t.Fatalf("got: %d, expect: %d", p, k)
+			}
+		}
+	}
+
+	f.Insert(1)
+	if err := f.SetStable(false); err == nil {
+		t.Fatal("should report error when f is not empty")
+	}
+}
+
+func TestFibonacciHeap_StableMeld(t *testing.T) {
+	var f1, f2, f3 FibonacciHeap
+	_ = f1.SetStable(true)
+	_ = f2.SetStable(true)
+
+	var hd []DataNode
+	for i := 0; i < 64; i++ {
+		if i%3 == 0 {
+			hd = append(hd, f1.Insert(7))
+		} else {
+			hd = append(hd, f2.Insert(7))
+		}
+	}
+	// consolidate both heaps before melding
+	f1.Insert(0)
+	f2.Insert(0)
+	_, _ = f1.DeleteMin()
+	_, _ = f2.DeleteMin()
+
+	if err := f1.Meld(&f2); err != nil {
+		t.Fatal(err)
+	}
+	for i, h := range hd {
+		if f1.min != h {
+			t.Fatalf("element #%d should be the minimum", i)
+		}
+		_, _ = f1.DeleteMin()
+	}
+
+	if err := f1.Meld(&f3); err == nil {
+		t.Fatal("should report error when only one heap is stable")
+	}
+}
+
+func TestFibonacciHeap_StableDecreaseKey(t *testing.T) {
+	f := FibonacciHeap{}
+	_ = f.SetStable(true)
+
+	var hd []DataNode
+	for i := 0; i < 32; i++ {
+		hd = append(hd, f.Insert(10+i))
+	}
+	_, _ = f.DeleteMin()
+
+	// decrease in reverse order, so the nodes are cut in reverse order
+	for i := len(hd) - 1; i > 0; i-- {
+		if err := f.DecreaseKey(hd[i], 5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i < len(hd); i++ {
+		if f.min != hd[i] {
+			t.Fatalf("element #%d should be the minimum", i)
+		}
+		_, _ = f.DeleteMin()
+	}
+}
+
+func TestFibonacciHeap_IncreaseKey(t *testing.T) {
+	f := FibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = f.Insert(v)
+	}
+
+	// consolidate the trees so that some nodes have children
+	f.Insert(-1)
+	if p, _ := f.DeleteMin(); p != -1 {
+		t.Fatalf("got: %d, expect: -1", p)
+	}
+
+	for _, v := range []int{1, 8, 4, 2, 3} {
+		err := f.IncreaseKey(hd[v], v+10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hd[v].Data() != v+10 {
+			t.Fatalf("got: %d, expect: %d", hd[v].Data(), v+10)
+		}
+	}
+
+	// the handles stay valid after the keys are increased
+	if err := f.DecreaseKey(hd[8], 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ans := range []int{0, 5, 6, 7, 9, 11, 12, 13, 14} {
+		p, err := f.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !f.Empty() {
+		t.Fatal("f should be empty")
+	}
+}
+
+func TestFibonacciHeap_IncreaseKey2(t *testing.T) {
+	f := FibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6} {
+		hd[v] = f.Insert(v)
+	}
+
+	err := f.IncreaseKey(hd[5], 1)
+	if err == nil {
+		t.Fatal("decrease a key is not valid")
+	}
+
+	err = f.IncreaseKey(&bHeapNode{data: 5}, 10)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+
+	err = f.UpdateKey(&bHeapNode{data: 5}, 10)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+}
+
+func TestFibonacciHeap_IncreaseKeyStale(t *testing.T) {
+	f := FibonacciHeap{}
+	popped := f.Insert(1)
+	f.Insert(3)
+	_, _ = f.DeleteMin()
+
+	if err := f.IncreaseKey(popped, 5); err == nil {
+		t.Fatal("should report that the handle is no longer in the heap")
+	}
+	for _, key := range []int{0, 1, 5} {
+		if err := f.UpdateKey(popped, key); err == nil {
+			t.Fatal("should report that the handle is no longer in the heap")
+		}
+	}
+	if f.n != 1 {
+		t.Fatalf("got: %d, expect: %d", f.n, 1)
+	}
+	if p, _ := f.Min(); p != 3 {
+		t.Fatalf("got: %d, expect: 3", p)
+	}
+}
+
+func TestFibonacciHeap_RandomUpdateKey(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	f := FibonacciHeap{}
+	live := map[DataNode]bool{}
+	var hd []DataNode
+
+	// keys are kept distinct so that the popped handle is known
+	for i := 0; i < 4096; i++ {
+		switch op := rng.Intn(10); {
+		case op < 4 || len(live) == 0:
+			h := f.Insert(rng.Intn(1<<16)<<12 | i)
+			hd = append(hd, h)
+			live[h] = true
+		case op < 8:
+			h := hd[rng.Intn(len(hd))]
+			if !live[h] {
+				continue
+			}
+			key := rng.Intn(1<<16)<<12 | i
+			if err := f.UpdateKey(h, key); err != nil {
+				t.Fatal(err)
+			}
+			if h.Data() != key {
+				t.Fatalf("got: %d, expect: %d", h.Data(), key)
+			}
+		default:
+			p, err := f.DeleteMin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want DataNode
+			for h := range live {
+				if h.Data() < p {
+					t.Fatalf("got: %d, but %d is smaller", p, h.Data())
+				}
+				if h.Data() == p {
+					want = h
+				}
+			}
+			delete(live, want)
+		}
+	}
+}
+
+func TestFibonacciHeap_MinNode(t *testing.T) {
+	f := FibonacciHeap{}
+	if _, err := f.MinNode(); err == nil {
+		t.Fatal("should report that f is empty")
+	}
+
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = f.Insert(v)
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		h, err := f.MinNode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != hd[ans] {
+			t.Fatalf("got: %d, expect: %d", h.Data(), ans)
+		}
+		if k, err := Key(h); err != nil || k != ans {
+			t.Fatalf("got: %d (%v), expect: %d", k, err, ans)
+		}
+
+		if _, err := f.DeleteMin(); err != nil {
+			t.Fatal(err)
+		}
+		if Valid(h) {
+			t.Fatal("the popped node should be invalid")
+		}
+		if _, err := Key(h); err == nil {
+			t.Fatal("should report that the node is not in the queue")
+		}
+	}
+}
+
+func TestFibonacciHeap_Valid(t *testing.T) {
+	f := FibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = f.Insert(v)
+	}
+	f.DeleteMin()
+
+	if _, err := f.Delete(hd[4]); err != nil {
+		t.Fatal(err)
+	}
+	if Valid(hd[4]) {
+		t.Fatal("the deleted node should be invalid")
+	}
+	if err := f.IncreaseKey(hd[2], 20); err != nil {
+		t.Fatal(err)
+	}
+	if !Valid(hd[2]) {
+		t.Fatal("the node should be still valid after its key is increased")
+	}
+	if k, err := Key(hd[2]); err != nil || k != 20 {
+		t.Fatalf("got: %d (%v), expect: 20", k, err)
+	}
+
+	var nilNode *fHeapNode
+	for _, h := range []DataNode{nil, nilNode, &foreignNode{}} {
+		if Valid(h) {
+			t.Fatalf("%v should be invalid", h)
+		}
+		if _, err := Key(h); err == nil {
+			t.Fatalf("should report incorrect type of %v", h)
+		}
+	}
+}
+
+// foreignNode is a DataNode that does not come from any priority queue.
+type foreignNode struct{}
+
+func (w *foreignNode) Data() int {
+	return 0
+}
+
+func TestFibonacciHeap_InsertMany(t *testing.T) {
+	f := FibonacciHeap{}
+	f.Insert(10)
+	f.Insert(0)
+
+	hd := f.InsertMany([]int{5, 2, 7, 6, 9, 1, 8, 4, 3})
+	for i, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		if hd[i].Data() != v {
+			t.Fatalf("got: %d, expect: %d", hd[i].Data(), v)
+		}
+	}
+	if len(f.InsertMany(nil)) != 0 {
+		t.Fatal("nothing should be inserted")
+	}
+
+	for ans := 0; ans <= 10; ans++ {
+		p, err := f.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !f.Empty() {
+		t.Fatal("f should be empty")
+	}
+}
+
+func TestFibonacciHeap_DeleteMinN(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	f := FibonacciHeap{}
+	var keys []int
+	for i := 0; i < 1024; i++ {
+		x := rng.Intn(256)
+		keys = append(keys, x)
+	}
+	f.InsertMany(keys[:512])
+	for _, x := range keys[512:] {
+		f.Insert(x)
+	}
+	slices.Sort(keys)
+
+	for len(keys) > 0 {
+		k := rng.Intn(64)
+		got := f.DeleteMinN(k)
+		want := keys[:min(k, len(keys))]
+		if !slices.Equal(got, want) {
+			t.Fatalf("got: %v, expect: %v", got, want)
+		}
+		keys = keys[len(want):]
+
+		if x := rng.Intn(256); rng.Intn(2) == 0 {
+			f.Insert(x)
+			i, _ := slices.BinarySearch(keys, x)
+			keys = slices.Insert(keys, i, x)
+		}
+	}
+
+	if !f.Empty() {
+		t.Fatal("f should be empty")
+	}
+	if got := f.DeleteMinN(1); got != nil {
+		t.Fatalf("got: %v, expect: []", got)
+	}
+}
+
+func BenchmarkFibonacciHeap_Insert(b *testing.B) {
+	xs := rand.Perm(1 << 12)
+	for i := 0; i < b.N; i++ {
+		h := FibonacciHeap{}
+		for _, x := range xs {
+			h.Insert(x)
+		}
+	}
+}
+
+func BenchmarkFibonacciHeap_InsertMany(b *testing.B) {
+	xs := rand.Perm(1 << 12)
+	for i := 0; i < b.N; i++ {
+		h := FibonacciHeap{}
+		h.InsertMany(xs)
+	}
+}
+
+func BenchmarkFibonacciHeap_DeleteMin(b *testing.B) {
+	xs := rand.Perm(1 << 12)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		h := FibonacciHeap{}
+		h.InsertMany(xs)
+		b.StartTimer()
+
+		for j := 0; j < 1<<8; j++ {
+			if _, err := h.DeleteMin(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkFibonacciHeap_DeleteMinN(b *testing.B) {
+	xs := rand.Perm(1 << 12)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		h := FibonacciHeap{}
+		h.InsertMany(xs)
+		b.StartTimer()
+
+		h.DeleteMinN(1 << 8)
+	}
+}
+
+func TestFibonacciHeap_ExtractUpTo(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	f := FibonacciHeap{}
+	var keys []int
+	for i := 0; i < 1024; i++ {
+		x := rng.Intn(4096)
+		f.Insert(x)
+		keys = append(keys, x)
+	}
+	slices.Sort(keys)
+
+	for limit := -1; len(keys) > 0; limit += rng.Intn(256) {
+		if got := f.CountUpTo(limit); got != countUpTo(keys, limit) {
+			t.Fatalf("got: %d, expect: %d", got, countUpTo(keys, limit))
+		}
+
+		got := f.ExtractUpTo(limit)
+		want := keys[:countUpTo(keys, limit)]
+		if !slices.Equal(got, want) {
+			t.Fatalf("got: %v, expect: %v", got, want)
+		}
+		keys = keys[len(want):]
+
+		if p, err := f.Min(); len(keys) > 0 && (err != nil || p != keys[0]) {
+			t.Fatalf("got: %d (%v), expect: %d", p, err, keys[0])
+		}
+	}
+
+	if !f.Empty() {
+		t.Fatal("f should be empty")
+	}
+}
+
+func TestFibonacciHeap_ExtractUpToSeq(t *testing.T) {
+	f := FibonacciHeap{}
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		f.Insert(v)
+	}
+
+	var got []int
+	for x := range f.ExtractUpToSeq(6) {
+		got = append(got, x)
+		if x == 3 {
+			break
+		}
+	}
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("got: %v, expect: [1 2 3]", got)
+	}
+
+	for ans := 4; ans < 10; ans++ {
+		p, err := f.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !f.Empty() {
+		t.Fatal("f should be empty")
+	}
+}
+
+func TestFibonacciHeap_VisitUpTo(t *testing.T) {
+	f := FibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3, 0} {
+		hd[v] = f.Insert(v)
+	}
+	f.DeleteMin()
+
+	visited := map[DataNode]bool{}
+	f.VisitUpTo(4, func(h DataNode) bool {
+		visited[h] = true
+		return true
+	})
+	if len(visited) != 4 {
+		t.Fatalf("got: %d, expect: 4", len(visited))
+	}
+	for v := 1; v <= 4; v++ {
+		if !visited[hd[v]] {
+			t.Fatalf("%d should be visited", v)
+		}
+	}
+
+	count := 0
+	f.VisitUpTo(9, func(DataNode) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Fatalf("got: %d, expect: 3", count)
+	}
+
+	if n := f.CountUpTo(100); n != 9 {
+		t.Fatalf("got: %d, expect: 9", n)
+	}
+}
+
+// countUpTo returns the number of keys in the sorted keys that are at most limit.
+func countUpTo(keys []int, limit int) int {
+	i, _ := slices.BinarySearch(keys, limit+1)
+	return i
+}
+
+func TestFibonacciHeap_Clone(t *testing.T) {
+	f := FibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3, 0} {
+		hd[v] = f.Insert(v)
+	}
+	f.DeleteMin()
+
+	c, handles := f.CloneWithHandles()
+	if len(handles) != 9 {
+		t.Fatalf("got: %d, expect: 9", len(handles))
+	}
+	for v := 1; v < 10; v++ {
+		if h := handles[hd[v]]; h == nil || h == hd[v] || h.Data() != v {
+			t.Fatalf("the handle of %d is not copied correctly", v)
+		}
+	}
+
+	// the copy is not affected by the operations on the original heap
+	f.DeleteMinN(5)
+	f.Insert(-1)
+	for ans := 1; ans < 10; ans++ {
+		p, err := c.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+	if !c.Empty() {
+		t.Fatal("c should be empty")
+	}
+
+	for _, ans := range []int{-1, 6, 7, 8, 9} {
+		p, err := f.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if e := (&FibonacciHeap{}).Clone(); !e.Empty() {
+		t.Fatal("the copy of an empty heap should be empty")
+	}
+}
+
+func TestFibonacciHeap_Clone2(t *testing.T) {
+	f := FibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3, 0} {
+		hd[v] = f.Insert(v)
+	}
+	f.DeleteMin()
+
+	c, handles := f.CloneWithHandles()
+	for _, v := range []int{9, 8, 7} {
+		if err := c.DecreaseKey(handles[hd[v]], -v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Delete(handles[hd[1]]); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ans := range []int{-9, -8, -7, 2, 3, 4, 5, 6} {
+		p, err := c.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		p, err := f.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+}
+
+var _ ThresholdPQ = (*FibonacciHeap)(nil)
+
+func TestFibonacciHeap_CountUpToPooled(t *testing.T) {
+	f := FibonacciHeap{}
+	f.SetPooled(true)
+	for _, x := range rand.Perm(64) {
+		f.Push(x)
+	}
+	_, _ = f.DeleteMin()
+
+	if got := f.CountUpTo(31); got != 31 {
+		t.Fatalf("got: %d, expect: %d", got, 31)
+	}
+	// counting hands no node out, so the popped nodes are still recycled
+	f.visitUpTo(64, func(x *fHeapNode) bool {
+		if x.escaped {
+			t.Fatalf("%d should not be escaped", x.data)
+		}
+		return true
+	})
+	free := f.pool.Free()
+	_, _ = f.DeleteMin()
+	if f.pool.Free() != free+1 {
+		t.Fatalf("got: %d, expect: %d", f.pool.Free(), free+1)
+	}
+}
+
+func TestFibonacciHeap_Pooled(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	f := FibonacciHeap{}
+	f.SetPooled(true)
+
+	var keys []int
+	var hd []DataNode
+	for i := 0; i < 4096; i++ {
+		if len(keys) == 0 || rng.Intn(3) > 0 {
+			x := rng.Intn(1 << 12)
+			if rng.Intn(4) == 0 {
+				hd = append(hd, f.Insert(x))
+			} else {
+				f.Push(x)
+			}
+			i, _ := slices.BinarySearch(keys, x)
+			keys = slices.Insert(keys, i, x)
+			continue
+		}
+
+		p, err := f.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != keys[0] {
+			t.Fatalf("got: %d, expect: %d", p, keys[0])
+		}
+		keys = keys[1:]
+	}
+
+	if got := f.DeleteMinN(len(keys)); !slices.Equal(got, keys) {
+		t.Fatalf("got: %v, expect: %v", got, keys)
+	}
+
+	// the popped nodes with handles are never recycled
+	for _, h := range hd {
+		if Valid(h) {
+			t.Fatalf("the handle of %d should be invalid", h.Data())
+		}
+	}
+}
+
+func BenchmarkFibonacciHeap_InsertDeleteMin(b *testing.B) {
+	b.ReportAllocs()
+	h := FibonacciHeap{}
+	for _, x := range rand.Perm(1 << 10) {
+		h.Insert(x)
+	}
+
+	for i := 0; i < b.N; i++ {
+		p, _ := h.DeleteMin()
+		h.Insert(p + 1<<10)
+	}
+}
+
+func BenchmarkFibonacciHeap_PooledPushDeleteMin(b *testing.B) {
+	b.ReportAllocs()
+	h := FibonacciHeap{}
+	h.SetPooled(true)
+	for _, x := range rand.Perm(1 << 10) {
+		h.Push(x)
+	}
+
+	for i := 0; i < b.N; i++ {
+		p, _ := h.DeleteMin()
+		h.Push(p + 1<<10)
+	}
+}
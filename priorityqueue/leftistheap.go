@@ -0,0 +1,133 @@
+package priorityqueue
+
+import "fmt"
+
+// LeftistHeap implementation that is introduced in
+// 'Fundamentals of Data Structures in C'
+//
+// Every node's left subtree has a right spine at least as long as its
+// right subtree's, so melding only walks the two short right spines.
+type LeftistHeap struct {
+	root *lHeapNode
+	n    int
+}
+
+// Min peeks and returns the minimum of the heap.
+func (l *LeftistHeap) Min() (int, error) {
+	if l.Empty() {
+		return 0, fmt.Errorf("heap is empty")
+	}
+	return l.root.data, nil
+}
+
+// MinNode peeks and returns the node of the minimum of the heap.
+func (l *LeftistHeap) MinNode() (DataNode, error) {
+	if l.Empty() {
+		return nil, fmt.Errorf("heap is empty")
+	}
+	return l.root, nil
+}
+
+// Empty returns whether the heap is empty or not.
+func (l *LeftistHeap) Empty() bool {
+	return l.root == nil
+}
+
+// Insert x into the LeftistHeap and return the inserted node.
+//
+// Actual cost is O(lg n).
+func (l *LeftistHeap) Insert(x int) DataNode {
+	node := &lHeapNode{data: x, rank: 1}
+	l.root = meldLeftist(l.root, node)
+	l.n++
+	return node
+}
+
+// InsertMany builds a LeftistHeap of xs bottom-up, melds it into l
+// and returns the inserted nodes in the order of xs.
+//
+// Actual cost is O(k + lg n), where k is len(xs).
+func (l *LeftistHeap) InsertMany(xs []int) []DataNode {
+	nodes := make([]DataNode, len(xs))
+	queue := make([]*lHeapNode, len(xs))
+	for i, x := range xs {
+		node := &lHeapNode{data: x, rank: 1}
+		nodes[i], queue[i] = node, node
+	}
+
+	// meld pairs of heaps round by round, like building a binary heap
+	for len(queue) > 1 {
+		queue = append(queue[2:], meldLeftist(queue[0], queue[1]))
+	}
+	if len(queue) == 1 {
+		l.root = meldLeftist(l.root, queue[0])
+	}
+
+	l.n += len(xs)
+	return nodes
+}
+
+// DeleteMin pops the minimum from the LeftistHeap then returns it,
+// error if the heap is empty.
+//
+// Actual cost is O(lg n).
+func (l *LeftistHeap) DeleteMin() (int, error) {
+	if l.Empty() {
+		return 0, fmt.Errorf("cannot delete-min from empty leftist heap")
+	}
+
+	minValue := l.root.data
+	l.root.removed = true
+	l.root = meldLeftist(l.root.left, l.root.right)
+	l.n--
+	return minValue, nil
+}
+
+// Meld two LeftistHeap and leave other empty,
+// error if the underlying type of other is not LeftistHeap.
+// Melding l with itself leaves it unchanged.
+//
+// Actual cost is O(lg n).
+func (l *LeftistHeap) Meld(other MeldablePQ) error {
+	if other, ok := other.(*LeftistHeap); ok {
+		if other == l {
+			return nil
+		}
+		l.root = meldLeftist(l.root, other.root)
+		l.n += other.n
+
+		other.root, other.n = nil, 0
+		return nil
+	}
+
+	return fmt.Errorf("cannot meld with non leftist heap")
+}
+
+// meldLeftist merges the right spines of a and b top-down,
+// then restores the leftist property bottom-up along the merged path.
+func meldLeftist(a, b *lHeapNode) *lHeapNode {
+	var path []*lHeapNode
+	for a != nil && b != nil {
+		if b.data < a.data {
+			a, b = b, a
+		}
+		path = append(path, a)
+		a = a.right
+	}
+
+	rest := a
+	if rest == nil {
+		rest = b
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		p := path[i]
+		p.right = rest
+		if p.left.Rank() < p.right.Rank() {
+			p.left, p.right = p.right, p.left
+		}
+		p.rank = p.right.Rank() + 1
+		rest = p
+	}
+	return rest
+}
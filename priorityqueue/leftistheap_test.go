@@ -0,0 +1,317 @@
+package priorityqueue
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLeftistHeap_Empty(t *testing.T) {
+	l := LeftistHeap{}
+	if !l.Empty() {
+		t.Fatal("l.Empty() should be true")
+	}
+
+	l.Insert(1)
+	l.Insert(2)
+	if l.Empty() {
+		t.Fatal("l.Empty() should be false after insertion")
+	}
+
+	_, _ = l.DeleteMin()
+	_, _ = l.DeleteMin()
+	if !l.Empty() {
+		t.Fatal("l.Empty() should be true after delete all elements")
+	}
+}
+
+func TestLeftistHeap_Insert(t *testing.T) {
+	l := LeftistHeap{}
+
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		hd := l.Insert(v)
+		if _, ok := hd.(*lHeapNode); !ok {
+			t.Fatal("incorrect underlying type")
+		}
+		if hd.Data() != v {
+			t.Fatalf("got: %d, expect: %d", hd.Data(), v)
+		}
+	}
+
+	if l.root.data != 1 {
+		t.Fatal("minimum of l should be 1, got", l.root.data)
+	}
+	if l.n != 5 {
+		t.Fatal("l.n should be 5, got", l.n)
+	}
+}
+
+func TestLeftistHeap_DeleteMin(t *testing.T) {
+	l := LeftistHeap{}
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		l.Insert(v)
+	}
+	for ans := 1; !l.Empty(); ans++ {
+		v, err := l.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != ans {
+			t.Fatalf("got: %d, expect: %d", v, ans)
+		}
+	}
+	_, err := l.DeleteMin()
+	if err == nil {
+		t.Fatal("should report error when l is empty")
+	}
+	if l.n != 0 {
+		t.Fatal("l.n should be 0")
+	}
+}
+
+func TestLeftistHeap_Min(t *testing.T) {
+	l := LeftistHeap{}
+
+	_, err := l.Min()
+	if err == nil {
+		t.Fatal("should report error when l is empty")
+	}
+
+	for _, v := range [][2]int{{5, 5}, {2, 2}, {4, 2}, {3, 2}, {1, 1}, {6, 1}} {
+		x, a := v[0], v[1]
+		l.Insert(x)
+		if y, err := l.Min(); y != a {
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Fatalf("got: %d, expect: %d", y, a)
+		}
+	}
+}
+
+func TestLeftistHeap_Meld(t *testing.T) {
+	var l1, l2 LeftistHeap
+
+	// l1 == l2 == empty
+	err := l1.Meld(&l2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l1.Empty() || !l2.Empty() {
+		t.Fatal("both l1 and l2 should be empty")
+	}
+
+	// l1 != empty, l2 == empty
+	l1.Insert(1)
+	l1.Insert(2)
+	err = l1.Meld(&l2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l2.Empty() || l2.n != 0 {
+		t.Fatal("l2 should be empty")
+	}
+	if l1.n != 2 {
+		t.Fatal("l1.n should be 2")
+	}
+	if y, _ := l1.Min(); y != 1 {
+		t.Fatal("l1.Min() should be 1")
+	}
+
+	// l1 == empty, l2 != empty
+	l1, l2 = l2, l1
+	err = l1.Meld(&l2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l2.Empty() || l2.n != 0 {
+		t.Fatal("l2 should be empty")
+	}
+	if l1.n != 2 {
+		t.Fatal("l1.n should be 2")
+	}
+	if y, _ := l1.Min(); y != 1 {
+		t.Fatal("l1.Min() should be 1")
+	}
+}
+
+func TestLeftistHeap_Meld2(t *testing.T) {
+	var l1, l2 LeftistHeap
+	for _, v := range []int{5, 2, 7, 6, 9} {
+		l1.Insert(v)
+	}
+	for _, v := range []int{8, 3, 4, 1, 10} {
+		l2.Insert(v)
+	}
+
+	err := l1.Meld(&l2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !l2.Empty() || l2.n != 0 {
+		t.Fatal("l2 should be empty")
+	}
+
+	if l1.n != 10 {
+		t.Fatal("l1.n should be 10, got", l1.n)
+	}
+
+	if y, _ := l1.Min(); y != 1 {
+		t.Fatalf("got: %d, expect: 1", y)
+	}
+
+	for ans := 1; !l1.Empty(); ans++ {
+		v, _ := l1.DeleteMin()
+		if v != ans {
+			t.Fatalf("got %d, expect %d", v, ans)
+		}
+	}
+
+	if !l1.Empty() {
+		t.Fatal("l1 should be empty after delete elements")
+	}
+}
+
+func TestLeftistHeap_Meld3(t *testing.T) {
+	l1 := LeftistHeap{}
+	var m MeldablePQ
+
+	err := l1.Meld(m)
+	if err == nil {
+		t.Fatal("should report error when other is not Leftist Heap")
+	}
+}
+
+func TestLeftistHeap_MeldSelf(t *testing.T) {
+	l := LeftistHeap{}
+	for _, x := range []int{3, 1, 2} {
+		l.Insert(x)
+	}
+	if err := l.Meld(&l); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := l.Min(); p != 1 || l.n != 3 {
+		t.Fatalf("got: %d with %d elements, expect: 1 with 3 elements", p, l.n)
+	}
+	for ans := 1; !l.Empty(); ans++ {
+		if p, _ := l.DeleteMin(); p != ans {
+			t.Fatalf("got: %d, expect: %d", p, ans)
+		}
+	}
+}
+
+func TestLeftistHeap_InsertMany(t *testing.T) {
+	l := LeftistHeap{}
+	l.Insert(6)
+
+	xs := []int{5, 2, 8, 4, 3, 1, 7}
+	hd := l.InsertMany(xs)
+	if len(hd) != len(xs) {
+		t.Fatalf("got %d handles, expect %d", len(hd), len(xs))
+	}
+	for i, x := range xs {
+		if hd[i].Data() != x {
+			t.Fatalf("got: %d, expect: %d", hd[i].Data(), x)
+		}
+	}
+	if l.n != 8 {
+		t.Fatal("l.n should be 8, got", l.n)
+	}
+
+	for ans := 1; !l.Empty(); ans++ {
+		p, err := l.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Fatalf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	l.InsertMany(nil)
+	if !l.Empty() {
+		t.Fatal("l should be empty after inserting nothing")
+	}
+}
+
+func TestLeftistHeap_RandomMeld(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	heaps := make([]*LeftistHeap, 16)
+	var ref []int
+	for i := range heaps {
+		heaps[i] = &LeftistHeap{}
+		for j := rng.Intn(64); j > 0; j-- {
+			v := rng.Intn(256)
+			heaps[i].Insert(v)
+			ref = append(ref, v)
+		}
+	}
+
+	for len(heaps) > 1 {
+		err := heaps[0].Meld(heaps[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		heaps = append(heaps[2:], heaps[0])
+	}
+
+	l := heaps[0]
+	if l.n != len(ref) {
+		t.Fatalf("l.n should be %d, got %d", len(ref), l.n)
+	}
+
+	slices.Sort(ref)
+	for _, ans := range ref {
+		p, err := l.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Fatalf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !l.Empty() {
+		t.Fatal("l should be empty")
+	}
+}
+
+func TestLeftistHeap_MinNode(t *testing.T) {
+	l := LeftistHeap{}
+	if _, err := l.MinNode(); err == nil {
+		t.Fatal("should report that l is empty")
+	}
+
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = l.Insert(v)
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		h, err := l.MinNode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != hd[ans] {
+			t.Fatalf("got: %d, expect: %d", h.Data(), ans)
+		}
+		if k, err := Key(h); err != nil || k != ans {
+			t.Fatalf("got: %d (%v), expect: %d", k, err, ans)
+		}
+
+		if _, err := l.DeleteMin(); err != nil {
+			t.Fatal(err)
+		}
+		if Valid(h) {
+			t.Fatal("the popped node should be invalid")
+		}
+		if _, err := Key(h); err == nil {
+			t.Fatal("should report that the node is not in the queue")
+		}
+	}
+}
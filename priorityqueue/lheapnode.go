@@ -0,0 +1,23 @@
+package priorityqueue
+
+type lHeapNode struct {
+	data        int
+	rank        int // length of the right spine, 0 for an absent node
+	left, right *lHeapNode
+	removed     bool
+}
+
+func (n *lHeapNode) Data() int {
+	return n.data
+}
+
+func (n *lHeapNode) valid() bool {
+	return n != nil && !n.removed
+}
+
+func (n *lHeapNode) Rank() int {
+	if n == nil {
+		return 0
+	}
+	return n.rank
+}
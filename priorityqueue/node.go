@@ -1,62 +1,103 @@
 package priorityqueue
 
-import "reflect"
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// insertSeq numbers inserted elements across all heaps,
+// so that equal keys can be ordered by their insertion time even after melds.
+var insertSeq atomic.Uint64
 
 // DataNode exposes only one API, which returns the contained data
 type DataNode interface {
 	Data() int
 }
 
+// handle is implemented by all nodes handed out by the priority queues.
+// valid must be safe to call on a nil pointer.
+type handle interface {
+	DataNode
+	valid() bool
+}
+
+// Valid reports whether the element of h is still in a priority queue,
+// that is, it has been neither popped nor deleted.
+func Valid(h DataNode) bool {
+	n, ok := h.(handle)
+	return ok && n.valid()
+}
+
+// Key returns the current key of h,
+// error if h is no longer in a priority queue or the type of h is incorrect.
+func Key(h DataNode) (int, error) {
+	if n, ok := h.(handle); !ok {
+		return 0, fmt.Errorf("incorrect type of handle")
+	} else if !n.valid() {
+		return 0, fmt.Errorf("handle is no longer in the queue")
+	}
+	return h.Data(), nil
+}
+
 // BiDirTreeNode defines operations of bidirectional linked-list node,
 // which supports getting/setting next/prev node,
-// and add new node to the children list
-type BiDirTreeNode interface {
+// and add new node to the children list.
+//
+// N is the concrete pointer type of the node itself, so that the helpers
+// below work on the concrete nodes without reflection or interface boxing.
+type BiDirTreeNode[N any] interface {
+	comparable
 	DataNode
-	Next() BiDirTreeNode
-	Prev() BiDirTreeNode
-	SetNext(n BiDirTreeNode)
-	SetPrev(n BiDirTreeNode)
-	AddChild(ch BiDirTreeNode)
+	Seq() uint64
+	Next() N
+	Prev() N
+	SetNext(n N)
+	SetPrev(n N)
+	AddChild(ch N)
 }
 
-func isNilPtr(x interface{}) bool {
-	return x == nil || (reflect.ValueOf(x).Kind() == reflect.Ptr && reflect.ValueOf(x).IsNil())
+func isOnly[N BiDirTreeNode[N]](x N) bool {
+	return x == x.Next()
 }
 
-func isOnly(x BiDirTreeNode) bool {
-	return x == x.Next()
+// lessNode compares the data of x and y,
+// and breaks ties by insertion order if stable is true.
+func lessNode[N BiDirTreeNode[N]](x, y N, stable bool) bool {
+	return x.Data() < y.Data() || stable && x.Data() == y.Data() && x.Seq() < y.Seq()
 }
 
-func findMinNode(list BiDirTreeNode) BiDirTreeNode {
-	if isNilPtr(list) {
+func findMinNode[N BiDirTreeNode[N]](list N, stable bool) N {
+	var null N
+	if list == null {
 		return list
 	}
 
 	min := list
 	for curr := list.Next(); curr != list; curr = curr.Next() {
-		if curr.Data() < min.Data() {
+		if lessNode(curr, min, stable) {
 			min = curr
 		}
 	}
 	return min
 }
 
-func mergeLists(x, y BiDirTreeNode) {
+func mergeLists[N BiDirTreeNode[N]](x, y N) {
 	x.Next().SetPrev(y.Prev())
 	y.Prev().SetNext(x.Next())
 	x.SetNext(y)
 	y.SetPrev(x)
 }
 
-func joinMinTrees(x, y BiDirTreeNode) BiDirTreeNode {
-	if isNilPtr(y) {
+func joinMinTrees[N BiDirTreeNode[N]](x, y N, stable bool) N {
+	var null N
+	if y == null {
 		return x
 	}
-	if isNilPtr(x) {
+	if x == null {
 		return y
 	}
 
-	if x.Data() < y.Data() {
+	if lessNode(x, y, stable) {
 		x.AddChild(y)
 		return x
 	} else {
@@ -64,3 +105,58 @@ func joinMinTrees(x, y BiDirTreeNode) BiDirTreeNode {
 		return y
 	}
 }
+
+// nodeHeap is a binary heap of tree nodes,
+// which visits heap-ordered trees in the order of their keys.
+type nodeHeap[T any] struct {
+	nodes []T
+	less  func(x, y T) bool
+}
+
+func (h *nodeHeap[T]) len() int {
+	return len(h.nodes)
+}
+
+// heapify restores the heap order after h.nodes is filled directly.
+func (h *nodeHeap[T]) heapify() {
+	for i := len(h.nodes)/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+}
+
+func (h *nodeHeap[T]) push(x T) {
+	h.nodes = append(h.nodes, x)
+	for i := len(h.nodes) - 1; i > 0; {
+		p := (i - 1) / 2
+		if !h.less(h.nodes[i], h.nodes[p]) {
+			break
+		}
+		h.nodes[i], h.nodes[p] = h.nodes[p], h.nodes[i]
+		i = p
+	}
+}
+
+func (h *nodeHeap[T]) pop() T {
+	last := len(h.nodes) - 1
+	x := h.nodes[0]
+	h.nodes[0] = h.nodes[last]
+	h.nodes = h.nodes[:last]
+	h.down(0)
+	return x
+}
+
+func (h *nodeHeap[T]) down(i int) {
+	for {
+		m := i
+		for _, c := range []int{2*i + 1, 2*i + 2} {
+			if c < len(h.nodes) && h.less(h.nodes[c], h.nodes[m]) {
+				m = c
+			}
+		}
+		if m == i {
+			return
+		}
+		h.nodes[i], h.nodes[m] = h.nodes[m], h.nodes[i]
+		i = m
+	}
+}
@@ -1,10 +1,13 @@
 package priorityqueue
 
+import "iter"
+
 type PriorityQueue interface {
 	Empty() bool
 	Insert(x int) DataNode
 	DeleteMin() (int, error)
 	Min() (int, error)
+	MinNode() (DataNode, error)
 }
 
 type MeldablePQ interface {
@@ -17,3 +20,20 @@ type CompletePQ interface {
 	Delete(target DataNode) (int, error)
 	DecreaseKey(target DataNode, key int) error
 }
+
+type UpdatablePQ interface {
+	CompletePQ
+	IncreaseKey(target DataNode, key int) error
+	UpdateKey(target DataNode, key int) error
+}
+
+// ThresholdPQ is a PriorityQueue that visits only the subtrees whose roots
+// are at most a threshold, to extract, count or visit the elements up to it.
+// It is implemented by FibonacciHeap and BinomialHeap only.
+type ThresholdPQ interface {
+	PriorityQueue
+	ExtractUpTo(limit int) []int
+	ExtractUpToSeq(limit int) iter.Seq[int]
+	CountUpTo(limit int) int
+	VisitUpTo(limit int, visit func(DataNode) bool)
+}
@@ -0,0 +1,354 @@
+package priorityqueue
+
+import "fmt"
+
+// RankPairingHeap implementation that is introduced in
+// 'Rank-Pairing Heaps' by B. Haeupler, S. Sen and R. E. Tarjan,
+// using one-pass linking and the type-2 rank rule.
+//
+// It achieves the same amortized bounds as FibonacciHeap,
+// but DecreaseKey only adjusts ranks instead of doing cascading cuts.
+type RankPairingHeap struct {
+	min   *rpHeapNode
+	n     int
+	owner *rpOwner // owner of the nodes of r, nil before the first insertion
+}
+
+// Min peeks and returns the minimum of the heap.
+func (r *RankPairingHeap) Min() (int, error) {
+	if r.Empty() {
+		return 0, fmt.Errorf("heap is empty")
+	}
+	return r.min.data, nil
+}
+
+// MinNode peeks and returns the node of the minimum of the heap.
+func (r *RankPairingHeap) MinNode() (DataNode, error) {
+	if r.Empty() {
+		return nil, fmt.Errorf("heap is empty")
+	}
+	return r.min, nil
+}
+
+// Empty returns whether the heap is empty or not.
+func (r *RankPairingHeap) Empty() bool {
+	return r.min == nil
+}
+
+// Insert x into the RankPairingHeap and return the inserted node.
+//
+// Actual cost is O(1).
+func (r *RankPairingHeap) Insert(x int) DataNode {
+	node := &rpHeapNode{data: x, owner: r.own()}
+	r.addRoot(node)
+	r.n++
+	return node
+}
+
+// own returns the owner of the nodes of r.
+func (r *RankPairingHeap) own() *rpOwner {
+	if r.owner == nil {
+		r.owner = &rpOwner{}
+	}
+	return r.owner
+}
+
+// owns returns whether x is a node of r.
+func (r *RankPairingHeap) owns(x *rpHeapNode) bool {
+	return r.owner != nil && x.owner.find() == r.owner
+}
+
+// addRoot puts the half-tree x into the root list and updates the min pointer.
+func (r *RankPairingHeap) addRoot(x *rpHeapNode) {
+	if r.min == nil {
+		r.min, x.right = x, x
+		return
+	}
+
+	x.right = r.min.right
+	r.min.right = x
+	if x.data < r.min.data {
+		r.min = x
+	}
+}
+
+// DeleteMin pops the minimum from the RankPairingHeap then returns it,
+// error if the heap is empty.
+//
+// Amortized cost is O(lg n).
+func (r *RankPairingHeap) DeleteMin() (int, error) {
+	if r.Empty() {
+		return 0, fmt.Errorf("cannot delete-min from empty rank-pairing heap")
+	}
+
+	m := r.min
+	m.removed = true
+	r.n--
+
+	// Step 1: break the right spine of m's child into new half-trees
+	var roots []*rpHeapNode
+	for x := m.left; x != nil; {
+		next := x.right
+		x.parent, x.right = nil, nil
+		x.rank = x.left.Rank() + 1
+		roots = append(roots, x)
+		x = next
+	}
+	for x := m.right; x != m; x = x.right {
+		roots = append(roots, x)
+	}
+	m.left, m.right = nil, nil
+
+	// Step 2: link half-trees of equal rank in one pass
+	var buckets, trees []*rpHeapNode
+	for _, x := range roots {
+		for len(buckets) <= x.rank {
+			buckets = append(buckets, nil)
+		}
+		if y := buckets[x.rank]; y != nil {
+			buckets[x.rank] = nil
+			trees = append(trees, linkHalfTrees(x, y))
+		} else {
+			buckets[x.rank] = x
+		}
+	}
+	for _, x := range buckets {
+		if x != nil {
+			trees = append(trees, x)
+		}
+	}
+
+	// Step 3: relink the half-trees and find min node
+	r.min = nil
+	for _, x := range trees {
+		r.addRoot(x)
+	}
+
+	return m.data, nil
+}
+
+// linkHalfTrees makes the root with larger key the left child of the other,
+// and the winner's old left subtree becomes the loser's right subtree.
+// x and y must have the same rank.
+func linkHalfTrees(x, y *rpHeapNode) *rpHeapNode {
+	if y.data < x.data {
+		x, y = y, x
+	}
+
+	y.right = x.left
+	if y.right != nil {
+		y.right.parent = y
+	}
+	x.left = y
+	y.parent = x
+	x.rank = y.rank + 1
+	return x
+}
+
+// Meld two RankPairingHeap and leave other empty,
+// error if the underlying type of other is not RankPairingHeap.
+// The handles of other become handles of r.
+//
+// Actual cost is O(1).
+func (r *RankPairingHeap) Meld(other MeldablePQ) error {
+	if other, ok := other.(*RankPairingHeap); ok {
+		if other.Empty() || other == r {
+			return nil
+		}
+		r.owner, other.owner = r.own().union(other.owner), nil
+		if r.Empty() {
+			r.min, r.n = other.min, other.n
+			other.min, other.n = nil, 0
+			return nil
+		}
+
+		r.min.right, other.min.right = other.min.right, r.min.right
+		if other.min.data < r.min.data {
+			r.min = other.min
+		}
+		r.n += other.n
+
+		other.min, other.n = nil, 0
+		return nil
+	}
+
+	return fmt.Errorf("cannot meld with non rank-pairing heap")
+}
+
+// Delete the specified arbitrary node in the RankPairingHeap r, error if r is
+// empty, the target is no longer in r, the target belongs to another heap
+// or the target's type is incorrect.
+//
+// Amortized cost is O(lg n).
+func (r *RankPairingHeap) Delete(target DataNode) (int, error) {
+	if r.Empty() {
+		return 0, fmt.Errorf("cannot delete from empty rank-pairing heap")
+	}
+
+	if target, ok := target.(*rpHeapNode); ok {
+		if !target.valid() {
+			return 0, fmt.Errorf("handle is no longer in the queue")
+		}
+		if !r.owns(target) {
+			return 0, fmt.Errorf("handle belongs to another queue")
+		}
+		if !target.isRoot() {
+			r.cut(target)
+			r.addRoot(target)
+		}
+
+		// treat target as if its key were minus infinity
+		r.min = target
+		return r.DeleteMin()
+	}
+
+	return 0, fmt.Errorf("incorrect type of target")
+}
+
+// DecreaseKey decrease the key of the specified node in r, error if key is
+// greater than original key, the target is no longer in r, the target
+// belongs to another heap or the target's type is incorrect.
+//
+// Amortized cost is O(1).
+func (r *RankPairingHeap) DecreaseKey(target DataNode, key int) error {
+	if target, ok := target.(*rpHeapNode); ok {
+		if !target.valid() {
+			return fmt.Errorf("handle is no longer in the queue")
+		}
+		if !r.owns(target) {
+			return fmt.Errorf("handle belongs to another queue")
+		}
+		if target.data < key {
+			return fmt.Errorf("new key is greater than original key")
+		}
+
+		target.data = key
+
+		if !target.isRoot() {
+			r.cut(target)
+			r.addRoot(target)
+		} else if target.data < r.min.data {
+			r.min = target
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
+// IncreaseKey increase the key of the specified node in r, error if key is
+// less than original key, the target is no longer in r, the target
+// belongs to another heap or the target's type is incorrect.
+// The target stays valid as a handle of the element.
+//
+// Amortized cost is O(lg n).
+func (r *RankPairingHeap) IncreaseKey(target DataNode, key int) error {
+	if target, ok := target.(*rpHeapNode); ok {
+		if !target.valid() {
+			return fmt.Errorf("handle is no longer in the queue")
+		}
+		if !r.owns(target) {
+			return fmt.Errorf("handle belongs to another queue")
+		}
+		if key < target.data {
+			return fmt.Errorf("new key is less than original key")
+		}
+
+		if target == r.min {
+			_, _ = r.DeleteMin()
+			target.data, target.rank, target.removed = key, 0, false
+			r.addRoot(target)
+			r.n++
+			return nil
+		}
+
+		if !target.isRoot() {
+			r.cut(target)
+			r.addRoot(target)
+		}
+
+		// the descendants of target may become smaller than it,
+		// so break the right spine of its child into new half-trees
+		for x := target.left; x != nil; {
+			next := x.right
+			x.parent, x.right = nil, nil
+			x.rank = x.left.Rank() + 1
+			r.addRoot(x)
+			x = next
+		}
+		target.left, target.rank = nil, 0
+		target.data = key
+
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
+// UpdateKey sets the key of the specified node in r to key,
+// by DecreaseKey or IncreaseKey depending on the original key,
+// error if the target is no longer in r, the target belongs to another heap
+// or the target's type is incorrect.
+func (r *RankPairingHeap) UpdateKey(target DataNode, key int) error {
+	if target, ok := target.(*rpHeapNode); ok {
+		if !target.valid() {
+			return fmt.Errorf("handle is no longer in the queue")
+		}
+		if !r.owns(target) {
+			return fmt.Errorf("handle belongs to another queue")
+		}
+		switch {
+		case key < target.data:
+			return r.DecreaseKey(target, key)
+		case key > target.data:
+			return r.IncreaseKey(target, key)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
+// cut detaches target with its left subtree from its half-tree,
+// lets its right subtree take its place,
+// and then decreases the ranks of its ancestors as the rank rule allows.
+func (r *RankPairingHeap) cut(target *rpHeapNode) {
+	p, y := target.parent, target.right
+	if p.left == target {
+		p.left = y
+	} else {
+		p.right = y
+	}
+	if y != nil {
+		y.parent = p
+	}
+
+	target.parent, target.right = nil, nil
+	target.rank = target.left.Rank() + 1
+
+	for u := p; ; u = u.parent {
+		if u.isRoot() {
+			u.rank = u.left.Rank() + 1
+			return
+		}
+
+		k := type2Rank(u.left.Rank(), u.right.Rank())
+		if k >= u.rank {
+			return
+		}
+		u.rank = k
+	}
+}
+
+// type2Rank computes the rank of a non-root node from its children's ranks,
+// so that every node is a 1,1-, 1,2- or 0,i-node.
+func type2Rank(r1, r2 int) int {
+	if r1 < r2 {
+		r1, r2 = r2, r1
+	}
+	if r1-r2 > 1 {
+		return r1
+	}
+	return r1 + 1
+}
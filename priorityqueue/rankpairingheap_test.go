@@ -0,0 +1,740 @@
+package priorityqueue
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestRankPairingHeap_Empty(t *testing.T) {
+	r := RankPairingHeap{}
+	if !r.Empty() {
+		t.Fatal("r.Empty() should be true")
+	}
+
+	r.Insert(1)
+	r.Insert(2)
+	if r.Empty() {
+		t.Fatal("r.Empty() should be false after insertion")
+	}
+
+	_, _ = r.DeleteMin()
+	_, _ = r.DeleteMin()
+	if !r.Empty() {
+		t.Fatal("r.Empty() should be true after delete all elements")
+	}
+}
+
+func TestRankPairingHeap_Insert(t *testing.T) {
+	r := RankPairingHeap{}
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		hd := r.Insert(v)
+		if _, ok := hd.(*rpHeapNode); !ok {
+			t.Fatal("incorrect underlying type")
+		}
+		if hd.Data() != v {
+			t.Fatalf("got: %d, expect: %d", hd.Data(), v)
+		}
+	}
+
+	if r.min.data != 1 {
+		t.Fatal("minimum of r should be 1, got", r.min.data)
+	}
+	if r.n != 5 {
+		t.Fatal("r.n should be 5, got", r.n)
+	}
+}
+
+func TestRankPairingHeap_DeleteMin(t *testing.T) {
+	r := RankPairingHeap{}
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		r.Insert(v)
+	}
+	for ans := 1; !r.Empty(); ans++ {
+		v, err := r.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != ans {
+			t.Fatalf("got: %d, expect: %d", v, ans)
+		}
+	}
+	_, err := r.DeleteMin()
+	if err == nil {
+		t.Fatal("should report error when r is empty")
+	}
+	if r.n != 0 {
+		t.Fatal("r.n should be 0")
+	}
+}
+
+func TestRankPairingHeap_Min(t *testing.T) {
+	r := RankPairingHeap{}
+
+	_, err := r.Min()
+	if err == nil {
+		t.Fatal("should report error when r is empty")
+	}
+
+	for _, v := range [][2]int{{5, 5}, {2, 2}, {4, 2}, {3, 2}, {1, 1}, {6, 1}} {
+		x, a := v[0], v[1]
+		r.Insert(x)
+		if y, err := r.Min(); y != a {
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Fatalf("get: %d, expect: %d", y, a)
+		}
+	}
+}
+
+func TestRankPairingHeap_Meld(t *testing.T) {
+	var r1, r2 RankPairingHeap
+
+	// r1 == r2 == empty
+	err := r1.Meld(&r2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r1.Empty() || !r2.Empty() {
+		t.Fatal("both r1 and r2 should be empty")
+	}
+
+	// r1 != empty, r2 == empty
+	r1.Insert(1)
+	r1.Insert(2)
+	err = r1.Meld(&r2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r2.Empty() || r2.n != 0 {
+		t.Fatal("r2 should be empty")
+	}
+	if r1.n != 2 {
+		t.Fatal("r1.n should be 2")
+	}
+	if y, _ := r1.Min(); y != 1 {
+		t.Fatal("r1.Min() should be 1")
+	}
+
+	// r1 == empty, r2 != empty
+	r1, r2 = r2, r1
+	err = r1.Meld(&r2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r2.Empty() || r2.n != 0 {
+		t.Fatal("r2 should be empty")
+	}
+	if r1.n != 2 {
+		t.Fatal("r1.n should be 2")
+	}
+	if y, _ := r1.Min(); y != 1 {
+		t.Fatal("r1.Min() should be 1")
+	}
+}
+
+func TestRankPairingHeap_Meld2(t *testing.T) {
+	var r1, r2 RankPairingHeap
+	for _, v := range []int{5, 2, 7, 6, 9} {
+		r1.Insert(v)
+	}
+	for _, v := range []int{8, 3, 4, 1, 10} {
+		r2.Insert(v)
+	}
+
+	err := r1.Meld(&r2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !r2.Empty() || r2.n != 0 {
+		t.Fatal("r2 should be empty")
+	}
+
+	if r1.n != 10 {
+		t.Fatal("r1.n should be 10, got", r1.n)
+	}
+
+	if y, _ := r1.Min(); y != 1 {
+		t.Fatalf("got: %d, expect: 1", y)
+	}
+
+	for ans := 1; !r1.Empty(); ans++ {
+		v, _ := r1.DeleteMin()
+		if v != ans {
+			t.Fatalf("got %d, expect %d", v, ans)
+		}
+	}
+
+	if !r1.Empty() || r1.n != 0 {
+		t.Fatal("r1 should be empty after delete elements")
+	}
+}
+
+func TestRankPairingHeap_Meld3(t *testing.T) {
+	r1 := RankPairingHeap{}
+	var m MeldablePQ
+
+	err := r1.Meld(m)
+	if err == nil {
+		t.Fatal("should report error when other is not Rank-Pairing Heap")
+	}
+}
+
+func TestRankPairingHeap_Delete(t *testing.T) {
+	r := RankPairingHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = r.Insert(v)
+	}
+
+	n := 8
+	for _, v := range []int{1, 8, 4, 2, 3} {
+		p, err := r.Delete(hd[v])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != v {
+			t.Fatalf("got: %d, expect: %d", p, v)
+		}
+		if r.n != n {
+			t.Fatal("r.n should be", n)
+		}
+		n--
+	}
+
+	if p, _ := r.Min(); p != 5 {
+		t.Fatalf("got: %d, expect: 5", p)
+	}
+
+	for _, v := range []int{6, 5, 9, 7} {
+		p, err := r.Delete(hd[v])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != v {
+			t.Fatalf("got: %d, expect: %d", p, v)
+		}
+		if r.n != n {
+			t.Fatal("r.n should be", n)
+		}
+		n--
+	}
+
+	if !r.Empty() {
+		t.Fatal("r should be empty: ", r.min.data)
+	}
+}
+
+func TestRankPairingHeap_Delete2(t *testing.T) {
+	r := RankPairingHeap{}
+	fn := &rpHeapNode{data: 0}
+	_, err := r.Delete(fn)
+	if err == nil {
+		t.Fatal("should report empty deletion")
+	}
+
+	r.Insert(5)
+
+	bn := &bHeapNode{data: 5}
+	_, err = r.Delete(bn)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+}
+
+func TestRankPairingHeap_StaleHandle(t *testing.T) {
+	r := RankPairingHeap{}
+	hd := r.Insert(3)
+	r.Insert(5)
+	if _, err := r.Delete(hd); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Delete(hd); err == nil {
+		t.Fatal("should report that the handle is no longer in the heap")
+	}
+	if err := r.DecreaseKey(hd, 1); err == nil {
+		t.Fatal("should report that the handle is no longer in the heap")
+	}
+	if err := r.IncreaseKey(hd, 7); err == nil {
+		t.Fatal("should report that the handle is no longer in the heap")
+	}
+	if p, _ := r.Min(); p != 5 {
+		t.Fatalf("got: %d, expect: 5", p)
+	}
+
+	// a handle popped by DeleteMin from a heap that becomes empty
+	_, _ = r.DeleteMin()
+	if err := r.DecreaseKey(hd, 1); err == nil {
+		t.Fatal("should report that the handle is no longer in the heap")
+	}
+	if !r.Empty() {
+		t.Fatal("r should be empty")
+	}
+}
+
+func TestRankPairingHeap_ForeignHandle(t *testing.T) {
+	a, b := RankPairingHeap{}, RankPairingHeap{}
+	ha := a.Insert(3)
+	a.Insert(7)
+	b.Insert(5)
+
+	if _, err := b.Delete(ha); err == nil {
+		t.Fatal("should report that the handle belongs to another heap")
+	}
+	if err := b.DecreaseKey(ha, 1); err == nil {
+		t.Fatal("should report that the handle belongs to another heap")
+	}
+	if err := b.IncreaseKey(ha, 9); err == nil {
+		t.Fatal("should report that the handle belongs to another heap")
+	}
+	if p, _ := b.Min(); p != 5 || b.n != 1 {
+		t.Fatalf("got: %d with %d elements, expect: 5 with 1 element", p, b.n)
+	}
+	if p, _ := a.Min(); p != 3 || a.n != 2 {
+		t.Fatalf("got: %d with %d elements, expect: 3 with 2 elements", p, a.n)
+	}
+
+	// the handles of a melded heap belong to the heap melded into
+	c := RankPairingHeap{}
+	hc := c.Insert(4)
+	if err := b.Meld(&a); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Meld(&b); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DecreaseKey(ha, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Delete(ha); err == nil {
+		t.Fatal("should report that the handle belongs to another heap")
+	}
+	a.Insert(2)
+	if _, err := a.Delete(hc); err == nil {
+		t.Fatal("should report that the handle belongs to another heap")
+	}
+	for _, ans := range []int{1, 4, 5, 7} {
+		if p, _ := c.DeleteMin(); p != ans {
+			t.Fatalf("got: %d, expect: %d", p, ans)
+		}
+	}
+	if !c.Empty() {
+		t.Fatal("c should be empty")
+	}
+}
+
+func TestRankPairingHeap_MeldSelf(t *testing.T) {
+	r := RankPairingHeap{}
+	for _, v := range []int{3, 1, 2} {
+		r.Insert(v)
+	}
+	if err := r.Meld(&r); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := r.Min(); p != 1 || r.n != 3 {
+		t.Fatalf("got: %d with %d elements, expect: 1 with 3 elements", p, r.n)
+	}
+}
+
+func TestRankPairingHeap_RandomDelete(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	r := RankPairingHeap{}
+	hd := make([]DataNode, 130)
+
+	insert := rng.Perm(128)
+	t.Logf("insert values: %v", insert)
+	for _, v := range insert {
+		hd[v] = r.Insert(v)
+	}
+	min, err := r.DeleteMin()
+	if err != nil {
+		t.Fatal(r)
+	}
+	if min != 0 {
+		t.Fatalf("got: %d, expect: %d", min, 0)
+	}
+
+	n := 127
+	deletion := rng.Perm(128)
+	t.Logf("delete values: %v", deletion)
+	for _, v := range deletion {
+		if v == 0 || hd[v] == nil {
+			continue
+		}
+
+		p, err := r.Delete(hd[v])
+		n--
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != v {
+			t.Fatalf("got: %d, expect: %d", p, v)
+		}
+		if r.n != n {
+			t.Fatal("r.n should be", n)
+		}
+	}
+
+	if !r.Empty() {
+		t.Fatal("r should be empty: ", r.min.data)
+	}
+}
+
+func TestRankPairingHeap_DecreaseKey(t *testing.T) {
+	r := RankPairingHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = r.Insert(v)
+	}
+
+	for _, v := range []int{1, 8, 4, 2, 3} {
+		err := r.DecreaseKey(hd[v], -v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if p, _ := r.DeleteMin(); p != -8 {
+		t.Fatalf("got: %d, expect: -8", p)
+	}
+
+	for _, ans := range []int{-4, -3, -2, -1, 5, 6, 7, 9} {
+		p, err := r.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !r.Empty() {
+		t.Fatal("r should be empty: ", r.min.data)
+	}
+}
+
+func TestRankPairingHeap_DecreaseKey2(t *testing.T) {
+	r := RankPairingHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6} {
+		hd[v] = r.Insert(v)
+	}
+
+	err := r.DecreaseKey(hd[5], 10)
+	if err == nil {
+		t.Fatal("increase a key is not valid")
+	}
+
+	err = r.DecreaseKey(&bHeapNode{data: 5}, 1)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+}
+
+func TestRankPairingHeap_RandomDecreaseKey(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	r := RankPairingHeap{}
+	hd := make([]DataNode, 130)
+
+	insert := rng.Perm(128)
+	t.Logf("insert values: %v", insert)
+	for _, v := range insert {
+		hd[v] = r.Insert(v)
+	}
+
+	min, err := r.DeleteMin()
+	if err != nil {
+		t.Fatal(r)
+	}
+	if min != 0 {
+		t.Fatalf("got: %d, expect: %d", min, 0)
+	}
+
+	decrement := rng.Perm(128)
+	t.Logf("decrease values: %v", decrement)
+	for _, v := range decrement {
+		if v == 0 || hd[v] == nil {
+			continue
+		}
+
+		err := r.DecreaseKey(hd[v], -v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for ans := -127; ans < 0; ans++ {
+		p, err := r.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !r.Empty() {
+		t.Fatal("r should be empty: ", r.min.data)
+	}
+}
+
+// checkHalfTree verifies heap order, parent pointers and the type-2 rank rule
+// of the subtree rooted at x, and returns its size.
+func checkHalfTree(t *testing.T, x *rpHeapNode, root int) int {
+	if x == nil {
+		return 0
+	}
+	if x.data < root {
+		t.Fatalf("heap order is violated: %d < %d", x.data, root)
+	}
+	for _, c := range []*rpHeapNode{x.left, x.right} {
+		if c != nil && c.parent != x {
+			t.Fatalf("incorrect parent pointer of %d", c.data)
+		}
+	}
+
+	r1, r2 := x.left.Rank(), x.right.Rank()
+	if r1 < r2 {
+		r1, r2 = r2, r1
+	}
+	if d1, d2 := x.rank-r1, x.rank-r2; !(d1 == 1 && d2 <= 2 || d1 == 0 && d2 >= 1) {
+		t.Fatalf("node %d with rank %d violates the rank rule", x.data, x.rank)
+	}
+
+	// keys in the right subtree are only bounded by the half-tree root
+	return 1 + checkHalfTree(t, x.left, x.data) + checkHalfTree(t, x.right, root)
+}
+
+func checkRankPairingHeap(t *testing.T, r *RankPairingHeap) {
+	if r.Empty() {
+		return
+	}
+
+	size := 0
+	x := r.min
+	for {
+		if x.data < r.min.data {
+			t.Fatalf("min pointer is not the minimum: %d < %d", x.data, r.min.data)
+		}
+		if x.parent != nil || x.rank != x.left.Rank()+1 {
+			t.Fatalf("root %d is malformed", x.data)
+		}
+		size += 1 + checkHalfTree(t, x.left, x.data)
+
+		x = x.right
+		if x == r.min {
+			break
+		}
+	}
+
+	if size != r.n {
+		t.Fatalf("got %d nodes, expect %d", size, r.n)
+	}
+}
+
+func TestRankPairingHeap_RandomOperations(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	r := RankPairingHeap{}
+	live := map[DataNode]bool{}
+	var hd []DataNode
+
+	for i := 0; i < 4096; i++ {
+		switch op := rng.Intn(10); {
+		case op < 4 || len(live) == 0:
+			h := r.Insert(rng.Intn(1 << 16))
+			hd = append(hd, h)
+			live[h] = true
+		case op < 7:
+			h := hd[rng.Intn(len(hd))]
+			if !live[h] {
+				continue
+			}
+			if err := r.DecreaseKey(h, h.Data()-rng.Intn(1<<10)); err != nil {
+				t.Fatal(err)
+			}
+		case op < 8:
+			h := hd[rng.Intn(len(hd))]
+			if !live[h] {
+				continue
+			}
+			if _, err := r.Delete(h); err != nil {
+				t.Fatal(err)
+			}
+			delete(live, h)
+		default:
+			want := r.min
+			p, err := r.DeleteMin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for h := range live {
+				if h.Data() < p {
+					t.Fatalf("got: %d, but %d is smaller", p, h.Data())
+				}
+			}
+			delete(live, want)
+		}
+		checkRankPairingHeap(t, &r)
+	}
+}
+
+func TestRankPairingHeap_IncreaseKey(t *testing.T) {
+	r := RankPairingHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = r.Insert(v)
+	}
+
+	// consolidate the trees so that some nodes have children
+	r.Insert(-1)
+	if p, _ := r.DeleteMin(); p != -1 {
+		t.Fatalf("got: %d, expect: -1", p)
+	}
+
+	for _, v := range []int{1, 8, 4, 2, 3} {
+		err := r.IncreaseKey(hd[v], v+10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hd[v].Data() != v+10 {
+			t.Fatalf("got: %d, expect: %d", hd[v].Data(), v+10)
+		}
+	}
+
+	// the handles stay valid after the keys are increased
+	if err := r.DecreaseKey(hd[8], 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ans := range []int{0, 5, 6, 7, 9, 11, 12, 13, 14} {
+		p, err := r.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !r.Empty() {
+		t.Fatal("r should be empty")
+	}
+}
+
+func TestRankPairingHeap_IncreaseKey2(t *testing.T) {
+	r := RankPairingHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6} {
+		hd[v] = r.Insert(v)
+	}
+
+	err := r.IncreaseKey(hd[5], 1)
+	if err == nil {
+		t.Fatal("decrease a key is not valid")
+	}
+
+	err = r.IncreaseKey(&fHeapNode{data: 5}, 10)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+
+	err = r.UpdateKey(&fHeapNode{data: 5}, 10)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+}
+
+func TestRankPairingHeap_RandomUpdateKey(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	r := RankPairingHeap{}
+	live := map[DataNode]bool{}
+	var hd []DataNode
+
+	// keys are kept distinct so that the popped handle is known
+	for i := 0; i < 4096; i++ {
+		switch op := rng.Intn(10); {
+		case op < 4 || len(live) == 0:
+			h := r.Insert(rng.Intn(1<<16)<<12 | i)
+			hd = append(hd, h)
+			live[h] = true
+		case op < 8:
+			h := hd[rng.Intn(len(hd))]
+			if !live[h] {
+				continue
+			}
+			key := rng.Intn(1<<16)<<12 | i
+			if err := r.UpdateKey(h, key); err != nil {
+				t.Fatal(err)
+			}
+			if h.Data() != key {
+				t.Fatalf("got: %d, expect: %d", h.Data(), key)
+			}
+		default:
+			p, err := r.DeleteMin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want DataNode
+			for h := range live {
+				if h.Data() < p {
+					t.Fatalf("got: %d, but %d is smaller", p, h.Data())
+				}
+				if h.Data() == p {
+					want = h
+				}
+			}
+			delete(live, want)
+		}
+		checkRankPairingHeap(t, &r)
+	}
+}
+
+func TestRankPairingHeap_MinNode(t *testing.T) {
+	r := RankPairingHeap{}
+	if _, err := r.MinNode(); err == nil {
+		t.Fatal("should report that r is empty")
+	}
+
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = r.Insert(v)
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		h, err := r.MinNode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != hd[ans] {
+			t.Fatalf("got: %d, expect: %d", h.Data(), ans)
+		}
+		if k, err := Key(h); err != nil || k != ans {
+			t.Fatalf("got: %d (%v), expect: %d", k, err, ans)
+		}
+
+		if _, err := r.DeleteMin(); err != nil {
+			t.Fatal(err)
+		}
+		if Valid(h) {
+			t.Fatal("the popped node should be invalid")
+		}
+		if _, err := Key(h); err == nil {
+			t.Fatal("should report that the node is not in the queue")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package priorityqueue
+
+// rpHeapNode is a node of a half-tree, where left points to the first child
+// and right points to the next sibling. Roots have no siblings, so right
+// links the root list instead.
+type rpHeapNode struct {
+	data        int
+	rank        int
+	left, right *rpHeapNode
+	parent      *rpHeapNode
+	owner       *rpOwner
+	removed     bool
+}
+
+// rpOwner identifies the RankPairingHeap holding a node. When two heaps are
+// melded, their owners are united like union-find sets, whose roots belong
+// to the heaps, so checking the owner of a node takes O(α(n)) amortized.
+type rpOwner struct {
+	next *rpOwner
+	rank int
+}
+
+// union links the sets of the roots o and p, and returns the new root.
+func (o *rpOwner) union(p *rpOwner) *rpOwner {
+	if o.rank < p.rank {
+		o, p = p, o
+	}
+	if o.rank == p.rank {
+		o.rank++
+	}
+	p.next = o
+	return o
+}
+
+// find returns the root of the set of o, halving the path on the way.
+func (o *rpOwner) find() *rpOwner {
+	for o.next != nil {
+		if o.next.next != nil {
+			o.next = o.next.next
+		}
+		o = o.next
+	}
+	return o
+}
+
+func (n *rpHeapNode) Data() int {
+	return n.data
+}
+
+func (n *rpHeapNode) valid() bool {
+	return n != nil && !n.removed
+}
+
+func (n *rpHeapNode) Rank() int {
+	if n == nil {
+		return -1
+	}
+	return n.rank
+}
+
+func (n *rpHeapNode) isRoot() bool {
+	return n.parent == nil
+}
@@ -0,0 +1,187 @@
+package priorityqueue
+
+// sfItem is the handle returned to the users of StrictFibonacciHeap.
+// Items may move between nodes, so the heap never hands out its nodes.
+type sfItem struct {
+	data int
+	seq  uint64
+	node *sfHeapNode // nil after the item is deleted
+}
+
+func (it *sfItem) Data() int {
+	return it.data
+}
+
+func (it *sfItem) valid() bool {
+	return it != nil && it.node != nil
+}
+
+func (it *sfItem) less(other *sfItem) bool {
+	return it.data < other.data || it.data == other.data && it.seq < other.seq
+}
+
+// sfActive is shared by all active nodes of a heap,
+// so that a whole heap can be made passive in O(1) time.
+type sfActive struct {
+	alive bool
+}
+
+type sfFixKind int
+
+const (
+	sfFixNone sfFixKind = iota
+	sfFixActiveRoot
+	sfFixLossOne
+	sfFixLossTwo
+)
+
+// indices of StrictFibonacciHeap.pairs and sfRank.pair
+const (
+	sfRootPairs = iota
+	sfLossPairs
+)
+
+// sfRank is a record of the rank list. Every active node points to the
+// record of its rank, and the record collects the active roots and the
+// nodes of loss one with that rank.
+type sfRank struct {
+	rank       int
+	prev, next *sfRank
+
+	roots, lossOne   *sfHeapNode
+	nRoots, nLossOne int
+
+	// links in StrictFibonacciHeap.pairs
+	pair [2]struct{ prev, next *sfRank }
+}
+
+func (r *sfRank) succ() *sfRank {
+	if r.next == nil {
+		r.next = &sfRank{rank: r.rank + 1, prev: r}
+	}
+	return r.next
+}
+
+type sfHeapNode struct {
+	item *sfItem
+
+	parent, child *sfHeapNode // child is the leftmost child
+	left, right   *sfHeapNode
+	qprev, qnext  *sfHeapNode
+
+	active *sfActive
+	rank   *sfRank // number of active children, valid only if active
+	loss   int     // valid only if active
+
+	fix              sfFixKind
+	fixRank          *sfRank
+	fixPrev, fixNext *sfHeapNode
+}
+
+func (n *sfHeapNode) less(other *sfHeapNode) bool {
+	return n.item.less(other.item)
+}
+
+func (n *sfHeapNode) isActive() bool {
+	return n != nil && n.active != nil && n.active.alive
+}
+
+// isActiveRoot reports whether n is an active node with a passive parent.
+func (n *sfHeapNode) isActiveRoot() bool {
+	return n.isActive() && !n.parent.isActive()
+}
+
+// linkable reports whether n is a passive node with only passive children.
+// Active children are always on the left, so only the leftmost one is checked.
+func (n *sfHeapNode) linkable() bool {
+	return !n.isActive() && !n.child.isActive()
+}
+
+// AddChild links ch as the leftmost child of n if leftmost is true,
+// otherwise as the rightmost child.
+func (n *sfHeapNode) AddChild(ch *sfHeapNode, leftmost bool) {
+	ch.parent = n
+	if n.child == nil {
+		n.child, ch.left, ch.right = ch, ch, ch
+		return
+	}
+
+	n.child.AddSibling(ch)
+	if leftmost {
+		n.child = ch
+	}
+}
+
+// AddSibling puts s on the left of n.
+func (n *sfHeapNode) AddSibling(s *sfHeapNode) {
+	s.right = n
+	s.left = n.left
+	n.left.right = s
+	n.left = s
+}
+
+func (n *sfHeapNode) removeChild(ch *sfHeapNode) {
+	if ch.right == ch {
+		n.child = nil
+	} else {
+		if n.child == ch {
+			n.child = ch.right
+		}
+		ch.left.right = ch.right
+		ch.right.left = ch.left
+	}
+	ch.parent, ch.left, ch.right = nil, ch, ch
+}
+
+func pushFix(head **sfHeapNode, x *sfHeapNode) {
+	if *head == nil {
+		*head, x.fixPrev, x.fixNext = x, x, x
+		return
+	}
+
+	h := *head
+	x.fixNext = h
+	x.fixPrev = h.fixPrev
+	h.fixPrev.fixNext = x
+	h.fixPrev = x
+}
+
+func removeFix(head **sfHeapNode, x *sfHeapNode) {
+	if x.fixNext == x {
+		*head = nil
+	} else {
+		if *head == x {
+			*head = x.fixNext
+		}
+		x.fixPrev.fixNext = x.fixNext
+		x.fixNext.fixPrev = x.fixPrev
+	}
+	x.fixPrev, x.fixNext = nil, nil
+}
+
+func pushPair(head **sfRank, i int, r *sfRank) {
+	if *head == nil {
+		*head = r
+		r.pair[i].prev, r.pair[i].next = r, r
+		return
+	}
+
+	h := *head
+	r.pair[i].next = h
+	r.pair[i].prev = h.pair[i].prev
+	h.pair[i].prev.pair[i].next = r
+	h.pair[i].prev = r
+}
+
+func removePair(head **sfRank, i int, r *sfRank) {
+	if r.pair[i].next == r {
+		*head = nil
+	} else {
+		if *head == r {
+			*head = r.pair[i].next
+		}
+		r.pair[i].prev.pair[i].next = r.pair[i].next
+		r.pair[i].next.pair[i].prev = r.pair[i].prev
+	}
+	r.pair[i].prev, r.pair[i].next = nil, nil
+}
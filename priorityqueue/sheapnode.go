@@ -0,0 +1,15 @@
+package priorityqueue
+
+type sHeapNode struct {
+	data        int
+	left, right *sHeapNode
+	removed     bool
+}
+
+func (n *sHeapNode) Data() int {
+	return n.data
+}
+
+func (n *sHeapNode) valid() bool {
+	return n != nil && !n.removed
+}
@@ -0,0 +1,135 @@
+package priorityqueue
+
+import "fmt"
+
+// SkewHeap is the self-adjusting version of LeftistHeap described in
+// 'Self-Adjusting Heaps' by D. Sleator and R. Tarjan.
+//
+// It keeps no balance information and instead swaps the children of
+// every node on the merge path.
+type SkewHeap struct {
+	root *sHeapNode
+	n    int
+}
+
+// Min peeks and returns the minimum of the heap.
+func (s *SkewHeap) Min() (int, error) {
+	if s.Empty() {
+		return 0, fmt.Errorf("heap is empty")
+	}
+	return s.root.data, nil
+}
+
+// MinNode peeks and returns the node of the minimum of the heap.
+func (s *SkewHeap) MinNode() (DataNode, error) {
+	if s.Empty() {
+		return nil, fmt.Errorf("heap is empty")
+	}
+	return s.root, nil
+}
+
+// Empty returns whether the heap is empty or not.
+func (s *SkewHeap) Empty() bool {
+	return s.root == nil
+}
+
+// Insert x into the SkewHeap and return the inserted node.
+//
+// Amortized cost is O(lg n).
+func (s *SkewHeap) Insert(x int) DataNode {
+	node := &sHeapNode{data: x}
+	s.root = meldSkew(s.root, node)
+	s.n++
+	return node
+}
+
+// InsertMany builds a SkewHeap of xs bottom-up, melds it into s
+// and returns the inserted nodes in the order of xs.
+//
+// Amortized cost is O(k + lg n), where k is len(xs).
+func (s *SkewHeap) InsertMany(xs []int) []DataNode {
+	nodes := make([]DataNode, len(xs))
+	queue := make([]*sHeapNode, len(xs))
+	for i, x := range xs {
+		node := &sHeapNode{data: x}
+		nodes[i], queue[i] = node, node
+	}
+
+	// meld pairs of heaps round by round, like building a binary heap
+	for len(queue) > 1 {
+		queue = append(queue[2:], meldSkew(queue[0], queue[1]))
+	}
+	if len(queue) == 1 {
+		s.root = meldSkew(s.root, queue[0])
+	}
+
+	s.n += len(xs)
+	return nodes
+}
+
+// DeleteMin pops the minimum from the SkewHeap then returns it,
+// error if the heap is empty.
+//
+// Amortized cost is O(lg n).
+func (s *SkewHeap) DeleteMin() (int, error) {
+	if s.Empty() {
+		return 0, fmt.Errorf("cannot delete-min from empty skew heap")
+	}
+
+	minValue := s.root.data
+	s.root.removed = true
+	s.root = meldSkew(s.root.left, s.root.right)
+	s.n--
+	return minValue, nil
+}
+
+// Meld two SkewHeap and leave other empty,
+// error if the underlying type of other is not SkewHeap.
+// Melding s with itself leaves it unchanged.
+//
+// Amortized cost is O(lg n).
+func (s *SkewHeap) Meld(other MeldablePQ) error {
+	if other, ok := other.(*SkewHeap); ok {
+		if other == s {
+			return nil
+		}
+		s.root = meldSkew(s.root, other.root)
+		s.n += other.n
+
+		other.root, other.n = nil, 0
+		return nil
+	}
+
+	return fmt.Errorf("cannot meld with non skew heap")
+}
+
+// meldSkew merges the right spines of a and b top-down.
+// Each node on the merge path moves its left child to the right,
+// and takes the rest of the merge as its new left child.
+func meldSkew(a, b *sHeapNode) *sHeapNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if b.data < a.data {
+		a, b = b, a
+	}
+
+	root := a
+	for {
+		r := a.right
+		a.right = a.left
+		if r == nil {
+			a.left = b
+			break
+		}
+		if b.data < r.data {
+			r, b = b, r
+		}
+		a.left = r
+		a = r
+	}
+	return root
+}
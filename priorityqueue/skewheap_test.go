@@ -0,0 +1,317 @@
+package priorityqueue
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSkewHeap_Empty(t *testing.T) {
+	s := SkewHeap{}
+	if !s.Empty() {
+		t.Fatal("s.Empty() should be true")
+	}
+
+	s.Insert(1)
+	s.Insert(2)
+	if s.Empty() {
+		t.Fatal("s.Empty() should be false after insertion")
+	}
+
+	_, _ = s.DeleteMin()
+	_, _ = s.DeleteMin()
+	if !s.Empty() {
+		t.Fatal("s.Empty() should be true after delete all elements")
+	}
+}
+
+func TestSkewHeap_Insert(t *testing.T) {
+	s := SkewHeap{}
+
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		hd := s.Insert(v)
+		if _, ok := hd.(*sHeapNode); !ok {
+			t.Fatal("incorrect underlying type")
+		}
+		if hd.Data() != v {
+			t.Fatalf("got: %d, expect: %d", hd.Data(), v)
+		}
+	}
+
+	if s.root.data != 1 {
+		t.Fatal("minimum of s should be 1, got", s.root.data)
+	}
+	if s.n != 5 {
+		t.Fatal("s.n should be 5, got", s.n)
+	}
+}
+
+func TestSkewHeap_DeleteMin(t *testing.T) {
+	s := SkewHeap{}
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		s.Insert(v)
+	}
+	for ans := 1; !s.Empty(); ans++ {
+		v, err := s.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != ans {
+			t.Fatalf("got: %d, expect: %d", v, ans)
+		}
+	}
+	_, err := s.DeleteMin()
+	if err == nil {
+		t.Fatal("should report error when s is empty")
+	}
+	if s.n != 0 {
+		t.Fatal("s.n should be 0")
+	}
+}
+
+func TestSkewHeap_Min(t *testing.T) {
+	s := SkewHeap{}
+
+	_, err := s.Min()
+	if err == nil {
+		t.Fatal("should report error when s is empty")
+	}
+
+	for _, v := range [][2]int{{5, 5}, {2, 2}, {4, 2}, {3, 2}, {1, 1}, {6, 1}} {
+		x, a := v[0], v[1]
+		s.Insert(x)
+		if y, err := s.Min(); y != a {
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Fatalf("got: %d, expect: %d", y, a)
+		}
+	}
+}
+
+func TestSkewHeap_Meld(t *testing.T) {
+	var s1, s2 SkewHeap
+
+	// s1 == s2 == empty
+	err := s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s1.Empty() || !s2.Empty() {
+		t.Fatal("both s1 and s2 should be empty")
+	}
+
+	// s1 != empty, s2 == empty
+	s1.Insert(1)
+	s1.Insert(2)
+	err = s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s2.Empty() || s2.n != 0 {
+		t.Fatal("s2 should be empty")
+	}
+	if s1.n != 2 {
+		t.Fatal("s1.n should be 2")
+	}
+	if y, _ := s1.Min(); y != 1 {
+		t.Fatal("s1.Min() should be 1")
+	}
+
+	// s1 == empty, s2 != empty
+	s1, s2 = s2, s1
+	err = s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s2.Empty() || s2.n != 0 {
+		t.Fatal("s2 should be empty")
+	}
+	if s1.n != 2 {
+		t.Fatal("s1.n should be 2")
+	}
+	if y, _ := s1.Min(); y != 1 {
+		t.Fatal("s1.Min() should be 1")
+	}
+}
+
+func TestSkewHeap_Meld2(t *testing.T) {
+	var s1, s2 SkewHeap
+	for _, v := range []int{5, 2, 7, 6, 9} {
+		s1.Insert(v)
+	}
+	for _, v := range []int{8, 3, 4, 1, 10} {
+		s2.Insert(v)
+	}
+
+	err := s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !s2.Empty() || s2.n != 0 {
+		t.Fatal("s2 should be empty")
+	}
+
+	if s1.n != 10 {
+		t.Fatal("s1.n should be 10, got", s1.n)
+	}
+
+	if y, _ := s1.Min(); y != 1 {
+		t.Fatalf("got: %d, expect: 1", y)
+	}
+
+	for ans := 1; !s1.Empty(); ans++ {
+		v, _ := s1.DeleteMin()
+		if v != ans {
+			t.Fatalf("got %d, expect %d", v, ans)
+		}
+	}
+
+	if !s1.Empty() {
+		t.Fatal("s1 should be empty after delete elements")
+	}
+}
+
+func TestSkewHeap_Meld3(t *testing.T) {
+	s1 := SkewHeap{}
+	var m MeldablePQ
+
+	err := s1.Meld(m)
+	if err == nil {
+		t.Fatal("should report error when other is not Skew Heap")
+	}
+}
+
+func TestSkewHeap_MeldSelf(t *testing.T) {
+	s := SkewHeap{}
+	for _, x := range []int{3, 1, 2} {
+		s.Insert(x)
+	}
+	if err := s.Meld(&s); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := s.Min(); p != 1 || s.n != 3 {
+		t.Fatalf("got: %d with %d elements, expect: 1 with 3 elements", p, s.n)
+	}
+	for ans := 1; !s.Empty(); ans++ {
+		if p, _ := s.DeleteMin(); p != ans {
+			t.Fatalf("got: %d, expect: %d", p, ans)
+		}
+	}
+}
+
+func TestSkewHeap_InsertMany(t *testing.T) {
+	s := SkewHeap{}
+	s.Insert(6)
+
+	xs := []int{5, 2, 8, 4, 3, 1, 7}
+	hd := s.InsertMany(xs)
+	if len(hd) != len(xs) {
+		t.Fatalf("got %d handles, expect %d", len(hd), len(xs))
+	}
+	for i, x := range xs {
+		if hd[i].Data() != x {
+			t.Fatalf("got: %d, expect: %d", hd[i].Data(), x)
+		}
+	}
+	if s.n != 8 {
+		t.Fatal("s.n should be 8, got", s.n)
+	}
+
+	for ans := 1; !s.Empty(); ans++ {
+		p, err := s.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Fatalf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	s.InsertMany(nil)
+	if !s.Empty() {
+		t.Fatal("s should be empty after inserting nothing")
+	}
+}
+
+func TestSkewHeap_RandomMeld(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	heaps := make([]*SkewHeap, 16)
+	var ref []int
+	for i := range heaps {
+		heaps[i] = &SkewHeap{}
+		for j := rng.Intn(64); j > 0; j-- {
+			v := rng.Intn(256)
+			heaps[i].Insert(v)
+			ref = append(ref, v)
+		}
+	}
+
+	for len(heaps) > 1 {
+		err := heaps[0].Meld(heaps[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		heaps = append(heaps[2:], heaps[0])
+	}
+
+	s := heaps[0]
+	if s.n != len(ref) {
+		t.Fatalf("s.n should be %d, got %d", len(ref), s.n)
+	}
+
+	slices.Sort(ref)
+	for _, ans := range ref {
+		p, err := s.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Fatalf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !s.Empty() {
+		t.Fatal("s should be empty")
+	}
+}
+
+func TestSkewHeap_MinNode(t *testing.T) {
+	s := SkewHeap{}
+	if _, err := s.MinNode(); err == nil {
+		t.Fatal("should report that s is empty")
+	}
+
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = s.Insert(v)
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		h, err := s.MinNode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != hd[ans] {
+			t.Fatalf("got: %d, expect: %d", h.Data(), ans)
+		}
+		if k, err := Key(h); err != nil || k != ans {
+			t.Fatalf("got: %d (%v), expect: %d", k, err, ans)
+		}
+
+		if _, err := s.DeleteMin(); err != nil {
+			t.Fatal(err)
+		}
+		if Valid(h) {
+			t.Fatal("the popped node should be invalid")
+		}
+		if _, err := Key(h); err == nil {
+			t.Fatal("should report that the node is not in the queue")
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package priorityqueue
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// SoftHeap implementation that is introduced in
+// 'A Simpler Implementation and Analysis of Chazelle's Soft Heaps'
+// by H. Kaplan and U. Zwick.
+//
+// A SoftHeap trades accuracy for speed: items may be moved into a node with
+// a larger common key, which is called corruption, and are then extracted
+// in the order of the corrupted key. After any sequence of operations with
+// n insertions, at most εn items in the heap are corrupted, while Insert
+// and Meld take O(1) and DeleteMin takes O(lg(1/ε)) amortized time.
+//
+// It must be initialized by Init before use.
+type SoftHeap struct {
+	first *softTree
+	rank  int // upper bound of the ranks in the root list
+	r     int // nodes of rank greater than r may hold more than one item
+	n     int
+}
+
+// Init sets the error rate of s to eps and empties it.
+// A non-positive eps disables corruption entirely.
+func (s *SoftHeap) Init(eps float64) {
+	s.first, s.rank, s.n = nil, 0, 0
+	if eps <= 0 {
+		s.r = math.MaxInt
+	} else {
+		s.r = max(int(math.Ceil(math.Log2(1/eps))), 0) + 5
+	}
+}
+
+// Min peeks and returns the key of the item that DeleteMin would return,
+// which is not necessarily the minimum if that item is corrupted.
+func (s *SoftHeap) Min() (int, error) {
+	if s.Empty() {
+		return 0, fmt.Errorf("heap is empty")
+	}
+	return s.first.sufMin.root.list.data, nil
+}
+
+// MinNode peeks and returns the item that DeleteMin would return.
+func (s *SoftHeap) MinNode() (DataNode, error) {
+	if s.Empty() {
+		return nil, fmt.Errorf("heap is empty")
+	}
+	return s.first.sufMin.root.list, nil
+}
+
+// Empty returns whether the heap is empty or not.
+func (s *SoftHeap) Empty() bool {
+	return s.first == nil
+}
+
+// Insert x into the SoftHeap and return the inserted item.
+//
+// Amortized cost is O(1).
+func (s *SoftHeap) Insert(x int) DataNode {
+	it := &softItem{data: x}
+	node := &softNode{ckey: x, size: 1, list: it, tail: it, count: 1}
+	tree := &softTree{root: node}
+	tree.sufMin = tree
+
+	s.meldTrees(tree, 0)
+	s.n++
+	return it
+}
+
+// DeleteMin pops an item with minimum corrupted key from the SoftHeap
+// then returns its original key, error if the heap is empty.
+//
+// Amortized cost is O(lg(1/ε)).
+func (s *SoftHeap) DeleteMin() (int, error) {
+	it, _, err := s.DeleteMinItem()
+	if err != nil {
+		return 0, err
+	}
+	return it.Data(), nil
+}
+
+// DeleteMinItem pops an item with minimum corrupted key from the SoftHeap,
+// and returns it with whether it was corrupted, error if the heap is empty.
+//
+// Amortized cost is O(lg(1/ε)).
+func (s *SoftHeap) DeleteMinItem() (DataNode, bool, error) {
+	if s.Empty() {
+		return nil, false, fmt.Errorf("cannot delete-min from empty soft heap")
+	}
+
+	t := s.first.sufMin
+	x := t.root
+	it := x.popItem()
+	it.removed = true
+	corrupted := it.data < x.ckey
+	s.n--
+
+	if 2*x.count <= x.size {
+		if !x.isLeaf() {
+			s.sift(x)
+			s.updateSufMin(t)
+		} else if x.list == nil {
+			s.removeTree(t)
+			if t.prev != nil {
+				s.updateSufMin(t.prev)
+			}
+		}
+	}
+
+	return it, corrupted, nil
+}
+
+// Corrupted returns all items in s whose key has been corrupted.
+//
+// Actual cost is O(n).
+func (s *SoftHeap) Corrupted() []DataNode {
+	var res []DataNode
+	var visit func(x *softNode)
+	visit = func(x *softNode) {
+		if x == nil {
+			return
+		}
+		for it := x.list; it != nil; it = it.next {
+			if it.data < x.ckey {
+				res = append(res, it)
+			}
+		}
+		visit(x.left)
+		visit(x.right)
+	}
+
+	for t := s.first; t != nil; t = t.next {
+		visit(t.root)
+	}
+	return res
+}
+
+// Meld two SoftHeap and leave other empty,
+// error if the underlying type of other is not SoftHeap
+// or the two heaps have different error rates.
+//
+// Amortized cost is O(1).
+func (s *SoftHeap) Meld(other MeldablePQ) error {
+	if other, ok := other.(*SoftHeap); ok {
+		if other.r != s.r {
+			return fmt.Errorf("cannot meld soft heaps with different error rates")
+		}
+		if other.Empty() || other == s {
+			return nil
+		}
+
+		s.meldTrees(other.first, other.rank)
+		s.n += other.n
+
+		other.first, other.rank, other.n = nil, 0, 0
+		return nil
+	}
+
+	return fmt.Errorf("cannot meld with non soft heap")
+}
+
+// meldTrees merges the root list starting from q, whose maximum rank is
+// qRank, into s, and combines trees of the same rank like adding two
+// binary numbers.
+func (s *SoftHeap) meldTrees(q *softTree, qRank int) {
+	if s.first == nil {
+		s.first, s.rank = q, qRank
+		return
+	}
+
+	// merge the list with the smaller maximum rank into the other one
+	p := s.first
+	if qRank > s.rank {
+		p, q = q, p
+		qRank, s.rank = s.rank, qRank
+	}
+	s.first = p
+
+	var last *softTree
+	for x := p; q != nil; {
+		for ; x != nil && x.rank < q.rank; x = x.next {
+			last = x
+		}
+
+		next := q.next
+		if x != nil {
+			s.insertBefore(x, q)
+		} else {
+			last.next, q.prev, q.next = q, last, nil
+			last = q
+		}
+		q = next
+	}
+
+	s.repeatedCombine(qRank)
+}
+
+func (s *SoftHeap) insertBefore(x, t *softTree) {
+	t.next, t.prev = x, x.prev
+	if x.prev != nil {
+		x.prev.next = t
+	} else {
+		s.first = t
+	}
+	x.prev = t
+}
+
+func (s *SoftHeap) removeTree(t *softTree) {
+	if t.prev != nil {
+		t.prev.next = t.next
+	} else {
+		s.first = t.next
+	}
+	if t.next != nil {
+		t.next.prev = t.prev
+	}
+}
+
+// repeatedCombine combines trees of equal rank from the front of the root
+// list, which only has to go beyond rank k while there are carries.
+func (s *SoftHeap) repeatedCombine(k int) {
+	x := s.first
+	for x.next != nil {
+		if x.rank == x.next.rank {
+			if y := x.next.next; y != nil && y.rank == x.rank {
+				x = x.next
+				continue
+			}
+			x.root = s.combine(x.root, x.next.root)
+			x.rank = x.root.rank
+			s.removeTree(x.next)
+		} else if x.rank > k {
+			break
+		} else {
+			x = x.next
+		}
+	}
+
+	s.rank = max(s.rank, x.rank)
+	s.updateSufMin(x)
+}
+
+func (s *SoftHeap) combine(x, y *softNode) *softNode {
+	z := &softNode{left: x, right: y, rank: x.rank + 1, size: 1}
+	if z.rank > s.r {
+		z.size = (3*x.size + 1) / 2
+	}
+	s.sift(z)
+	return z
+}
+
+// sift refills the item list of x from its children,
+// always taking the whole list of the child with smaller ckey.
+func (s *SoftHeap) sift(x *softNode) {
+	for x.count < x.size && !x.isLeaf() {
+		if x.left == nil || x.right != nil && x.left.ckey > x.right.ckey {
+			x.left, x.right = x.right, x.left
+		}
+
+		x.takeList(x.left)
+		x.ckey = x.left.ckey
+
+		if x.left.isLeaf() {
+			x.left = nil
+		} else {
+			s.sift(x.left)
+		}
+	}
+}
+
+// updateSufMin recomputes the suffix minimums from t back to the first tree.
+func (s *SoftHeap) updateSufMin(t *softTree) {
+	for ; t != nil; t = t.prev {
+		if t.next == nil || t.root.ckey <= t.next.sufMin.root.ckey {
+			t.sufMin = t
+		} else {
+			t.sufMin = t.next.sufMin
+		}
+	}
+}
+
+// SoftSelect returns the k-th smallest element (counting from 0) of xs,
+// error if k is out of range. xs is reordered in place.
+//
+// A SoftHeap with error rate 1/3 picks a pivot whose rank is between n/3
+// and 2n/3, so the actual cost is O(n).
+func SoftSelect(xs []int, k int) (int, error) {
+	if k < 0 || k >= len(xs) {
+		return 0, fmt.Errorf("k = %d is out of range [0, %d)", k, len(xs))
+	}
+
+	for len(xs) > 16 {
+		var s SoftHeap
+		s.Init(1.0 / 3)
+		for _, x := range xs {
+			s.Insert(x)
+		}
+
+		pivot := math.MinInt
+		for range (len(xs) + 2) / 3 {
+			x, _ := s.DeleteMin()
+			pivot = max(pivot, x)
+		}
+
+		// three-way partition: [< pivot][== pivot][> pivot]
+		lt, i, gt := 0, 0, len(xs)
+		for i < gt {
+			switch {
+			case xs[i] < pivot:
+				xs[lt], xs[i] = xs[i], xs[lt]
+				lt++
+				i++
+			case xs[i] > pivot:
+				gt--
+				xs[gt], xs[i] = xs[i], xs[gt]
+			default:
+				i++
+			}
+		}
+
+		switch {
+		case k < lt:
+			xs = xs[:lt]
+		case k < gt:
+			return pivot, nil
+		default:
+			xs, k = xs[gt:], k-gt
+		}
+	}
+
+	slices.Sort(xs)
+	return xs[k], nil
+}
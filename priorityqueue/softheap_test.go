@@ -0,0 +1,326 @@
+package priorityqueue
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSoftHeap_Empty(t *testing.T) {
+	s := SoftHeap{}
+	s.Init(0.1)
+	if !s.Empty() {
+		t.Fatal("s.Empty() should be true")
+	}
+
+	s.Insert(1)
+	s.Insert(2)
+	if s.Empty() {
+		t.Fatal("s.Empty() should be false after insertion")
+	}
+
+	_, _ = s.DeleteMin()
+	_, _ = s.DeleteMin()
+	if !s.Empty() {
+		t.Fatal("s.Empty() should be true after delete all elements")
+	}
+}
+
+func TestSoftHeap_Insert(t *testing.T) {
+	s := SoftHeap{}
+	s.Init(0.1)
+
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		hd := s.Insert(v)
+		if _, ok := hd.(*softItem); !ok {
+			t.Fatal("incorrect underlying type")
+		}
+		if hd.Data() != v {
+			t.Fatalf("got: %d, expect: %d", hd.Data(), v)
+		}
+	}
+
+	if y, _ := s.Min(); y != 1 {
+		t.Fatal("minimum of s should be 1, got", y)
+	}
+	if s.n != 5 {
+		t.Fatal("s.n should be 5, got", s.n)
+	}
+}
+
+func TestSoftHeap_DeleteMin(t *testing.T) {
+	s := SoftHeap{}
+	s.Init(0.1)
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		s.Insert(v)
+	}
+	for ans := 1; !s.Empty(); ans++ {
+		v, err := s.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != ans {
+			t.Fatalf("got: %d, expect: %d", v, ans)
+		}
+	}
+	_, err := s.DeleteMin()
+	if err == nil {
+		t.Fatal("should report error when s is empty")
+	}
+	if s.n != 0 {
+		t.Fatal("s.n should be 0")
+	}
+}
+
+func TestSoftHeap_Min(t *testing.T) {
+	s := SoftHeap{}
+	s.Init(0.1)
+
+	_, err := s.Min()
+	if err == nil {
+		t.Fatal("should report error when s is empty")
+	}
+
+	for _, v := range [][2]int{{5, 5}, {2, 2}, {4, 2}, {3, 2}, {1, 1}, {6, 1}} {
+		x, a := v[0], v[1]
+		s.Insert(x)
+		if y, err := s.Min(); y != a {
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Fatalf("got: %d, expect: %d", y, a)
+		}
+	}
+}
+
+func TestSoftHeap_Meld(t *testing.T) {
+	var s1, s2 SoftHeap
+	s1.Init(0.1)
+	s2.Init(0.1)
+
+	// s1 == s2 == empty
+	err := s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s1.Empty() || !s2.Empty() {
+		t.Fatal("both s1 and s2 should be empty")
+	}
+
+	// s1 != empty, s2 == empty
+	s1.Insert(1)
+	s1.Insert(2)
+	err = s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s2.Empty() || s2.n != 0 {
+		t.Fatal("s2 should be empty")
+	}
+	if s1.n != 2 {
+		t.Fatal("s1.n should be 2")
+	}
+	if y, _ := s1.Min(); y != 1 {
+		t.Fatal("s1.Min() should be 1")
+	}
+
+	// s1 == empty, s2 != empty
+	s1, s2 = s2, s1
+	err = s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s2.Empty() || s2.n != 0 {
+		t.Fatal("s2 should be empty")
+	}
+	if s1.n != 2 {
+		t.Fatal("s1.n should be 2")
+	}
+	if y, _ := s1.Min(); y != 1 {
+		t.Fatal("s1.Min() should be 1")
+	}
+}
+
+func TestSoftHeap_Meld2(t *testing.T) {
+	var s1, s2 SoftHeap
+	s1.Init(0.1)
+	s2.Init(0.1)
+	for _, v := range []int{5, 2, 7, 6, 9} {
+		s1.Insert(v)
+	}
+	for _, v := range []int{8, 3, 4, 1, 10} {
+		s2.Insert(v)
+	}
+
+	err := s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !s2.Empty() || s2.n != 0 {
+		t.Fatal("s2 should be empty")
+	}
+
+	if s1.n != 10 {
+		t.Fatal("s1.n should be 10, got", s1.n)
+	}
+
+	if y, _ := s1.Min(); y != 1 {
+		t.Fatalf("got: %d, expect: 1", y)
+	}
+
+	for ans := 1; !s1.Empty(); ans++ {
+		v, _ := s1.DeleteMin()
+		if v != ans {
+			t.Fatalf("got %d, expect %d", v, ans)
+		}
+	}
+
+	if !s1.Empty() {
+		t.Fatal("s1 should be empty after delete elements")
+	}
+}
+
+func TestSoftHeap_Meld3(t *testing.T) {
+	s1 := SoftHeap{}
+	s1.Init(0.1)
+	var m MeldablePQ
+
+	err := s1.Meld(m)
+	if err == nil {
+		t.Fatal("should report error when other is not Soft Heap")
+	}
+}
+
+func TestSoftHeap_Meld4(t *testing.T) {
+	var s1, s2 SoftHeap
+	s1.Init(0.1)
+	s2.Init(0.01)
+	s2.Insert(1)
+
+	err := s1.Meld(&s2)
+	if err == nil {
+		t.Fatal("should report error when error rates are different")
+	}
+}
+
+func TestSoftHeap_Corruption(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	for _, eps := range []float64{0.5, 0.1, 0.01} {
+		s := SoftHeap{}
+		s.Init(eps)
+
+		inserted := 0
+		for i := 0; i < 20000; i++ {
+			if rng.Intn(3) > 0 || s.Empty() {
+				s.Insert(rng.Intn(1 << 20))
+				inserted++
+			} else {
+				it, corrupted, err := s.DeleteMinItem()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if corrupted && slices.Contains(s.Corrupted(), it) {
+					t.Fatal("deleted item is still in the heap")
+				}
+			}
+
+			if i%1000 == 0 {
+				if c := len(s.Corrupted()); float64(c) > eps*float64(inserted) {
+					t.Fatalf("eps = %v: %d corrupted items after %d insertions", eps, c, inserted)
+				}
+			}
+		}
+	}
+}
+
+func TestSoftHeap_Exact(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	s := SoftHeap{}
+	s.Init(0)
+	ref := rng.Perm(4096)
+	for _, v := range ref {
+		s.Insert(v)
+	}
+
+	for ans := 0; ans < len(ref); ans++ {
+		it, corrupted, err := s.DeleteMinItem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if corrupted || it.Data() != ans {
+			t.Fatalf("got: %d, expect: %d", it.Data(), ans)
+		}
+	}
+}
+
+func TestSoftSelect(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	for _, n := range []int{1, 10, 100, 1000, 5000} {
+		xs := make([]int, n)
+		for i := range xs {
+			xs[i] = rng.Intn(n)
+		}
+		sorted := slices.Sorted(slices.Values(xs))
+
+		for _, k := range []int{0, n / 4, n / 2, n - 1} {
+			v, err := SoftSelect(xs, k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != sorted[k] {
+				t.Fatalf("n = %d, k = %d: got %d, expect %d", n, k, v, sorted[k])
+			}
+		}
+	}
+
+	if _, err := SoftSelect([]int{1, 2}, 2); err == nil {
+		t.Fatal("should report error when k is out of range")
+	}
+}
+
+func TestSoftHeap_MinNode(t *testing.T) {
+	s := SoftHeap{}
+	s.Init(0)
+	if _, err := s.MinNode(); err == nil {
+		t.Fatal("should report that s is empty")
+	}
+
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = s.Insert(v)
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		h, err := s.MinNode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != hd[ans] {
+			t.Fatalf("got: %d, expect: %d", h.Data(), ans)
+		}
+		if k, err := Key(h); err != nil || k != ans {
+			t.Fatalf("got: %d (%v), expect: %d", k, err, ans)
+		}
+
+		if _, err := s.DeleteMin(); err != nil {
+			t.Fatal(err)
+		}
+		if Valid(h) {
+			t.Fatal("the popped node should be invalid")
+		}
+		if _, err := Key(h); err == nil {
+			t.Fatal("should report that the node is not in the queue")
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package priorityqueue
+
+// softItem is an element of a SoftHeap, stored in the item list of a node.
+type softItem struct {
+	data    int
+	next    *softItem
+	removed bool
+}
+
+func (it *softItem) Data() int {
+	return it.data
+}
+
+func (it *softItem) valid() bool {
+	return it != nil && !it.removed
+}
+
+type softNode struct {
+	ckey        int // the common, possibly corrupted, key of all items in list
+	rank        int
+	size        int // target length of list
+	left, right *softNode
+
+	list, tail *softItem
+	count      int
+}
+
+func (n *softNode) isLeaf() bool {
+	return n.left == nil && n.right == nil
+}
+
+// takeList moves all items of other to the end of the list of n.
+func (n *softNode) takeList(other *softNode) {
+	if other.list == nil {
+		return
+	}
+
+	if n.list == nil {
+		n.list = other.list
+	} else {
+		n.tail.next = other.list
+	}
+	n.tail = other.tail
+	n.count += other.count
+
+	other.list, other.tail, other.count = nil, nil, 0
+}
+
+func (n *softNode) popItem() *softItem {
+	it := n.list
+	n.list = it.next
+	if n.list == nil {
+		n.tail = nil
+	}
+	n.count--
+
+	it.next = nil
+	return it
+}
+
+// softTree is an entry of the root list of a SoftHeap.
+type softTree struct {
+	root       *softNode
+	rank       int
+	prev, next *softTree
+	sufMin     *softTree // the tree with minimum ckey from this one to the end
+}
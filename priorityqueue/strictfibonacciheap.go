@@ -0,0 +1,605 @@
+package priorityqueue
+
+import "fmt"
+
+// StrictFibonacciHeap implementation that is introduced in
+// 'Strict Fibonacci Heaps' by G. S. Brodal, G. Lagogiannis and R. E. Tarjan.
+//
+// Unlike FibonacciHeap, all bounds are worst-case: Insert, Meld and
+// DecreaseKey take O(1) time and DeleteMin takes O(lg n) time, because the
+// heap is kept balanced by a constant number of reductions per operation
+// instead of a deferred consolidation.
+//
+// The heap is a single tree whose root holds the minimum. Nodes are either
+// active or passive; the active nodes carry ranks and losses like the nodes
+// of a FibonacciHeap, while passive nodes are plain tree nodes. Ties between
+// equal keys are broken by insertion order, so the tree is strictly ordered.
+type StrictFibonacciHeap struct {
+	root *sfHeapNode
+	n    int
+
+	q      *sfHeapNode // front of the queue of all non-root nodes
+	active *sfActive
+	ranks  *sfRank // record of rank 0
+
+	// The children of the root are ordered as active nodes, passive
+	// non-linkable nodes and then passive linkable nodes from left to right.
+	// rootPassive is the leftmost passive child of the root.
+	rootPassive *sfHeapNode
+
+	// the fix-list: ranks with two active roots or two nodes of loss one,
+	// and the nodes with loss at least two
+	pairs   [2]*sfRank
+	lossTwo *sfHeapNode
+}
+
+// Min peeks and returns the minimum of the heap.
+func (s *StrictFibonacciHeap) Min() (int, error) {
+	if s.Empty() {
+		return 0, fmt.Errorf("heap is empty")
+	}
+	return s.root.item.data, nil
+}
+
+// MinNode peeks and returns the node of the minimum of the heap.
+func (s *StrictFibonacciHeap) MinNode() (DataNode, error) {
+	if s.Empty() {
+		return nil, fmt.Errorf("heap is empty")
+	}
+	return s.root.item, nil
+}
+
+// Empty returns whether the heap is empty or not.
+func (s *StrictFibonacciHeap) Empty() bool {
+	return s.root == nil
+}
+
+// Insert x into the StrictFibonacciHeap and return the inserted node.
+//
+// Actual cost is O(1).
+func (s *StrictFibonacciHeap) Insert(x int) DataNode {
+	item := &sfItem{data: x, seq: insertSeq.Add(1)}
+	s.insertItem(item)
+	return item
+}
+
+// insertItem puts item into a new node and adds it into the heap.
+func (s *StrictFibonacciHeap) insertItem(item *sfItem) {
+	node := &sfHeapNode{item: item}
+	item.node = node
+	node.left, node.right = node, node
+	s.n++
+
+	if s.root == nil {
+		s.root = node
+		s.active = &sfActive{alive: true}
+		s.ranks = &sfRank{}
+		return
+	}
+
+	// meld with a heap of one node, which is the smaller one and stays passive
+	s.linkToRoot(node)
+	s.qPush(node)
+	if node.less(s.root) {
+		s.swapItems(node, s.root)
+	}
+
+	s.activeRootReduction()
+	s.rootDegreeReduction()
+}
+
+// DeleteMin pops the minimum from the StrictFibonacciHeap then returns it,
+// error if the heap is empty.
+//
+// Actual cost is O(lg n).
+func (s *StrictFibonacciHeap) DeleteMin() (int, error) {
+	if s.Empty() {
+		return 0, fmt.Errorf("cannot delete-min from empty strict fibonacci heap")
+	}
+
+	r := s.root
+	r.item.node = nil
+	minValue := r.item.data
+	s.n--
+
+	if r.child == nil {
+		*s = StrictFibonacciHeap{}
+		return minValue, nil
+	}
+
+	// Step 1: the child with minimum key becomes the new root
+	x := r.child
+	for c := r.child.right; c != r.child; c = c.right {
+		if c.less(x) {
+			x = c
+		}
+	}
+
+	var children []*sfHeapNode
+	for _, p := range []*sfHeapNode{r, x} {
+		for c := p.child; c != nil; c = p.child {
+			p.removeChild(c)
+			if c != x {
+				children = append(children, c)
+			}
+		}
+	}
+
+	s.qRemove(x)
+	if x.isActive() {
+		s.fixRemove(x)
+		x.active = nil
+	}
+	s.root, s.rootPassive = x, nil
+
+	// Step 2: link all children to x, in the order required for the root.
+	// Active children of x become active roots now.
+	for _, c := range children {
+		if c.isActive() {
+			c.loss = 0
+			s.linkToRoot(c)
+			s.refix(c)
+		}
+	}
+	for _, c := range children {
+		if !c.isActive() && !c.linkable() {
+			s.linkToRoot(c)
+		}
+	}
+	for _, c := range children {
+		if c.linkable() {
+			s.linkToRoot(c)
+		}
+	}
+
+	// Step 3: move two nodes to the back of the queue,
+	// and shed at most two passive children of each to the root
+	for range 2 {
+		y := s.q
+		if y == nil {
+			break
+		}
+		s.q = y.qnext
+
+		for range 2 {
+			if y.child == nil || y.child.left.isActive() {
+				break
+			}
+			z := y.child.left
+			s.removeChild(z)
+			s.linkToRoot(z)
+		}
+	}
+
+	// Step 4: restore the invariants
+	s.lossReduction()
+	for s.activeRootReduction() || s.rootDegreeReduction() {
+	}
+
+	return minValue, nil
+}
+
+// Meld two StrictFibonacciHeap and leave other empty,
+// error if the underlying type of other is not StrictFibonacciHeap.
+//
+// Actual cost is O(1).
+func (s *StrictFibonacciHeap) Meld(other MeldablePQ) error {
+	if other, ok := other.(*StrictFibonacciHeap); ok {
+		if other.Empty() || other == s {
+			return nil
+		}
+		if s.Empty() {
+			*s, *other = *other, StrictFibonacciHeap{}
+			return nil
+		}
+
+		// all nodes of the smaller heap become passive at once
+		big, small := s, other
+		if other.n > s.n {
+			big, small = other, s
+		}
+		small.active.alive = false
+
+		x, y := s.root, other.root
+		if y.less(x) {
+			x, y = y, x
+		}
+
+		melded := StrictFibonacciHeap{
+			root:    x,
+			n:       s.n + other.n,
+			q:       s.q,
+			active:  big.active,
+			ranks:   big.ranks,
+			pairs:   big.pairs,
+			lossTwo: big.lossTwo,
+		}
+		if x == big.root {
+			melded.rootPassive = big.rootPassive
+		} else {
+			// every child of the root is passive and linkable now
+			melded.rootPassive = x.child
+		}
+
+		// the queue becomes s.q, y, other.q
+		melded.qPush(y)
+		melded.qSplice(other.q)
+
+		*s, *other = melded, StrictFibonacciHeap{}
+		s.linkToRoot(y)
+
+		s.activeRootReduction()
+		s.rootDegreeReduction()
+		return nil
+	}
+
+	return fmt.Errorf("cannot meld with non strict fibonacci heap")
+}
+
+// Delete the specified arbitrary node in the StrictFibonacciHeap s,
+// error if s is empty, the target's type is incorrect
+// or the target is no longer in the heap.
+//
+// Actual cost is O(lg n).
+func (s *StrictFibonacciHeap) Delete(target DataNode) (int, error) {
+	if s.Empty() {
+		return 0, fmt.Errorf("cannot delete from empty strict fibonacci heap")
+	}
+
+	if target, ok := target.(*sfItem); ok {
+		if target.node == nil {
+			return 0, fmt.Errorf("target is not in the heap")
+		}
+
+		// treat target as if its key were minus infinity
+		s.moveUp(target.node, true)
+		return s.DeleteMin()
+	}
+
+	return 0, fmt.Errorf("incorrect type of target")
+}
+
+// DecreaseKey decrease the key of the specified node in s,
+// error if key is greater than original key, the target's type is incorrect
+// or the target is no longer in the heap.
+//
+// Actual cost is O(1).
+func (s *StrictFibonacciHeap) DecreaseKey(target DataNode, key int) error {
+	if target, ok := target.(*sfItem); ok {
+		if target.node == nil {
+			return fmt.Errorf("target is not in the heap")
+		}
+		if target.data < key {
+			return fmt.Errorf("new key is greater than original key")
+		}
+
+		target.data = key
+		s.moveUp(target.node, false)
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
+// IncreaseKey increase the key of the specified node in s,
+// error if key is less than original key, the target's type is incorrect
+// or the target is no longer in the heap.
+// The target stays valid as a handle of the element.
+//
+// Actual cost is O(lg n).
+func (s *StrictFibonacciHeap) IncreaseKey(target DataNode, key int) error {
+	if target, ok := target.(*sfItem); ok {
+		if target.node == nil {
+			return fmt.Errorf("target is not in the heap")
+		}
+		if key < target.data {
+			return fmt.Errorf("new key is less than original key")
+		}
+
+		// the subtree of target may hold smaller keys, so the item is
+		// deleted and then inserted again with the new key
+		_, _ = s.Delete(target)
+		target.data = key
+		s.insertItem(target)
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
+// UpdateKey sets the key of the specified node in s to key,
+// by DecreaseKey or IncreaseKey depending on the original key.
+func (s *StrictFibonacciHeap) UpdateKey(target DataNode, key int) error {
+	if target, ok := target.(*sfItem); ok {
+		switch {
+		case key < target.data:
+			return s.DecreaseKey(target, key)
+		case key > target.data:
+			return s.IncreaseKey(target, key)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("incorrect type of target")
+}
+
+// moveUp cuts node with its subtree and links it to the root after its key
+// is decreased. If the item of node becomes smaller than the root's item,
+// or toRoot is true, the two items are swapped.
+func (s *StrictFibonacciHeap) moveUp(node *sfHeapNode, toRoot bool) {
+	if node == s.root {
+		return
+	}
+	if toRoot || node.less(s.root) {
+		s.swapItems(node, s.root)
+	}
+
+	if p := node.parent; p != s.root {
+		wasActive := node.isActive()
+		s.removeChild(node)
+		node.loss = 0
+		s.linkToRoot(node)
+		s.refix(node)
+		if wasActive {
+			s.lostActiveChild(p)
+		}
+	}
+
+	s.lossReduction()
+	for i := 0; i < 6 && s.activeRootReduction(); i++ {
+	}
+	for i := 0; i < 4 && s.rootDegreeReduction(); i++ {
+	}
+}
+
+func (s *StrictFibonacciHeap) swapItems(a, b *sfHeapNode) {
+	a.item, b.item = b.item, a.item
+	a.item.node, b.item.node = a, b
+}
+
+// activeRootReduction links two active roots of the same rank,
+// and sheds a passive child of the winner to the root to keep its degree.
+func (s *StrictFibonacciHeap) activeRootReduction() bool {
+	r := s.pairs[sfRootPairs]
+	if r == nil {
+		return false
+	}
+
+	x, y := r.roots, r.roots.fixNext
+	if y.less(x) {
+		x, y = y, x
+	}
+
+	p := y.parent
+	s.removeChild(y)
+	x.AddChild(y, true)
+	x.rank = x.rank.succ()
+	s.refix(x)
+	s.refix(y)
+	s.lostActiveChild(p)
+
+	if z := x.child.left; !z.isActive() {
+		s.removeChild(z)
+		s.linkToRoot(z)
+	}
+	return true
+}
+
+// rootDegreeReduction turns the three rightmost children of the root into
+// an active root of rank one, if they are passive and linkable.
+func (s *StrictFibonacciHeap) rootDegreeReduction() bool {
+	if s.root.child == nil {
+		return false
+	}
+
+	z := s.root.child.left
+	y := z.left
+	x := y.left
+	if y == z || x == z || !x.linkable() || !y.linkable() || !z.linkable() {
+		return false
+	}
+
+	// sort x, y, z
+	if y.less(x) {
+		x, y = y, x
+	}
+	if z.less(y) {
+		y, z = z, y
+	}
+	if y.less(x) {
+		x, y = y, x
+	}
+
+	for _, c := range []*sfHeapNode{x, y, z} {
+		s.removeChild(c)
+	}
+
+	x.active, x.rank, x.loss, x.fix = s.active, s.ranks.succ(), 0, sfFixNone
+	y.active, y.rank, y.loss, y.fix = s.active, s.ranks, 0, sfFixNone
+	y.AddChild(z, false)
+	x.AddChild(y, true)
+	s.linkToRoot(x)
+	s.refix(x)
+	return true
+}
+
+// lossReduction does a one-node loss reduction if there is a node
+// with loss at least two, otherwise a two-node loss reduction
+// on two nodes of loss one and the same rank.
+func (s *StrictFibonacciHeap) lossReduction() bool {
+	if x := s.lossTwo; x != nil {
+		p := x.parent
+		s.removeChild(x)
+		x.loss = 0
+		s.linkToRoot(x)
+		s.refix(x)
+		s.lostActiveChild(p)
+		return true
+	}
+
+	if r := s.pairs[sfLossPairs]; r != nil {
+		x, y := r.lossOne, r.lossOne.fixNext
+		if y.less(x) {
+			x, y = y, x
+		}
+
+		p := y.parent
+		s.removeChild(y)
+		x.AddChild(y, true)
+		x.rank = x.rank.succ()
+		x.loss, y.loss = 0, 0
+		s.lostActiveChild(p)
+		s.refix(x)
+		s.refix(y)
+		return true
+	}
+
+	return false
+}
+
+// lostActiveChild updates p after one of its active children is removed.
+func (s *StrictFibonacciHeap) lostActiveChild(p *sfHeapNode) {
+	if p == s.root {
+		return
+	}
+
+	if p.isActive() {
+		p.rank = p.rank.prev
+		if !p.isActiveRoot() {
+			p.loss++
+		}
+		s.refix(p)
+	} else if p.parent == s.root && p.linkable() {
+		// p has just become linkable
+		s.removeChild(p)
+		s.linkToRoot(p)
+	}
+}
+
+// linkToRoot makes c a child of the root at the position required by its kind.
+func (s *StrictFibonacciHeap) linkToRoot(c *sfHeapNode) {
+	r := s.root
+	switch {
+	case c.isActive():
+		r.AddChild(c, true)
+	case c.linkable():
+		r.AddChild(c, false)
+		if s.rootPassive == nil {
+			s.rootPassive = c
+		}
+	default:
+		if b := s.rootPassive; b != nil {
+			c.parent = r
+			b.AddSibling(c)
+			if r.child == b {
+				r.child = c
+			}
+		} else {
+			r.AddChild(c, false)
+		}
+		s.rootPassive = c
+	}
+}
+
+func (s *StrictFibonacciHeap) removeChild(c *sfHeapNode) {
+	p := c.parent
+	if p == s.root && c == s.rootPassive {
+		if c.right == p.child {
+			s.rootPassive = nil
+		} else {
+			s.rootPassive = c.right
+		}
+	}
+	p.removeChild(c)
+}
+
+// refix moves x to the part of the fix-list matching its current state.
+func (s *StrictFibonacciHeap) refix(x *sfHeapNode) {
+	s.fixRemove(x)
+
+	switch {
+	case !x.isActive():
+	case x.isActiveRoot():
+		x.fix, x.fixRank = sfFixActiveRoot, x.rank
+		pushFix(&x.rank.roots, x)
+		if x.rank.nRoots++; x.rank.nRoots == 2 {
+			pushPair(&s.pairs[sfRootPairs], sfRootPairs, x.rank)
+		}
+	case x.loss == 1:
+		x.fix, x.fixRank = sfFixLossOne, x.rank
+		pushFix(&x.rank.lossOne, x)
+		if x.rank.nLossOne++; x.rank.nLossOne == 2 {
+			pushPair(&s.pairs[sfLossPairs], sfLossPairs, x.rank)
+		}
+	case x.loss >= 2:
+		x.fix = sfFixLossTwo
+		pushFix(&s.lossTwo, x)
+	}
+}
+
+func (s *StrictFibonacciHeap) fixRemove(x *sfHeapNode) {
+	// nodes made passive by Meld are still in the fix-list of a dead heap
+	if !x.isActive() {
+		x.fix, x.fixRank = sfFixNone, nil
+		return
+	}
+
+	r := x.fixRank
+	switch x.fix {
+	case sfFixActiveRoot:
+		removeFix(&r.roots, x)
+		if r.nRoots--; r.nRoots == 1 {
+			removePair(&s.pairs[sfRootPairs], sfRootPairs, r)
+		}
+	case sfFixLossOne:
+		removeFix(&r.lossOne, x)
+		if r.nLossOne--; r.nLossOne == 1 {
+			removePair(&s.pairs[sfLossPairs], sfLossPairs, r)
+		}
+	case sfFixLossTwo:
+		removeFix(&s.lossTwo, x)
+	}
+	x.fix, x.fixRank = sfFixNone, nil
+}
+
+func (s *StrictFibonacciHeap) qPush(x *sfHeapNode) {
+	if s.q == nil {
+		s.q, x.qprev, x.qnext = x, x, x
+		return
+	}
+
+	x.qnext = s.q
+	x.qprev = s.q.qprev
+	s.q.qprev.qnext = x
+	s.q.qprev = x
+}
+
+// qSplice appends the queue starting from front to the back of s.q.
+func (s *StrictFibonacciHeap) qSplice(front *sfHeapNode) {
+	if front == nil {
+		return
+	}
+	if s.q == nil {
+		s.q = front
+		return
+	}
+
+	tail := s.q.qprev
+	tail.qnext = front
+	s.q.qprev = front.qprev
+	front.qprev.qnext = s.q
+	front.qprev = tail
+}
+
+func (s *StrictFibonacciHeap) qRemove(x *sfHeapNode) {
+	if x.qnext == x {
+		s.q = nil
+	} else {
+		if s.q == x {
+			s.q = x.qnext
+		}
+		x.qprev.qnext = x.qnext
+		x.qnext.qprev = x.qprev
+	}
+	x.qprev, x.qnext = nil, nil
+}
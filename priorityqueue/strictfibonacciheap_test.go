@@ -0,0 +1,757 @@
+package priorityqueue
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestStrictFibonacciHeap_Empty(t *testing.T) {
+	s := StrictFibonacciHeap{}
+	if !s.Empty() {
+		t.Fatal("s.Empty() should be true")
+	}
+
+	s.Insert(1)
+	s.Insert(2)
+	if s.Empty() {
+		t.Fatal("s.Empty() should be false after insertion")
+	}
+
+	_, _ = s.DeleteMin()
+	_, _ = s.DeleteMin()
+	if !s.Empty() {
+		t.Fatal("s.Empty() should be true after delete all elements")
+	}
+}
+
+func TestStrictFibonacciHeap_Insert(t *testing.T) {
+	s := StrictFibonacciHeap{}
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		hd := s.Insert(v)
+		if _, ok := hd.(*sfItem); !ok {
+			t.Fatal("incorrect underlying type")
+		}
+		if hd.Data() != v {
+			t.Fatalf("got: %d, expect: %d", hd.Data(), v)
+		}
+	}
+
+	if s.root.item.data != 1 {
+		t.Fatal("minimum of s should be 1, got", s.root.item.data)
+	}
+	if s.n != 5 {
+		t.Fatal("s.n should be 5, got", s.n)
+	}
+}
+
+func TestStrictFibonacciHeap_DeleteMin(t *testing.T) {
+	s := StrictFibonacciHeap{}
+	for _, v := range []int{5, 2, 4, 3, 1} {
+		s.Insert(v)
+	}
+	for ans := 1; !s.Empty(); ans++ {
+		v, err := s.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != ans {
+			t.Fatalf("got: %d, expect: %d", v, ans)
+		}
+	}
+	_, err := s.DeleteMin()
+	if err == nil {
+		t.Fatal("should report error when s is empty")
+	}
+	if s.n != 0 {
+		t.Fatal("s.n should be 0")
+	}
+}
+
+func TestStrictFibonacciHeap_Min(t *testing.T) {
+	s := StrictFibonacciHeap{}
+
+	_, err := s.Min()
+	if err == nil {
+		t.Fatal("should report error when s is empty")
+	}
+
+	for _, v := range [][2]int{{5, 5}, {2, 2}, {4, 2}, {3, 2}, {1, 1}, {6, 1}} {
+		x, a := v[0], v[1]
+		s.Insert(x)
+		if y, err := s.Min(); y != a {
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Fatalf("get: %d, expect: %d", y, a)
+		}
+	}
+}
+
+func TestStrictFibonacciHeap_Meld(t *testing.T) {
+	var s1, s2 StrictFibonacciHeap
+
+	// s1 == s2 == empty
+	err := s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s1.Empty() || !s2.Empty() {
+		t.Fatal("both s1 and s2 should be empty")
+	}
+
+	// s1 != empty, s2 == empty
+	s1.Insert(1)
+	s1.Insert(2)
+	err = s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s2.Empty() || s2.n != 0 {
+		t.Fatal("s2 should be empty")
+	}
+	if s1.n != 2 {
+		t.Fatal("s1.n should be 2")
+	}
+	if y, _ := s1.Min(); y != 1 {
+		t.Fatal("s1.Min() should be 1")
+	}
+
+	// s1 == empty, s2 != empty
+	s1, s2 = s2, s1
+	err = s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s2.Empty() || s2.n != 0 {
+		t.Fatal("s2 should be empty")
+	}
+	if s1.n != 2 {
+		t.Fatal("s1.n should be 2")
+	}
+	if y, _ := s1.Min(); y != 1 {
+		t.Fatal("s1.Min() should be 1")
+	}
+}
+
+func TestStrictFibonacciHeap_Meld2(t *testing.T) {
+	var s1, s2 StrictFibonacciHeap
+	for _, v := range []int{5, 2, 7, 6, 9} {
+		s1.Insert(v)
+	}
+	for _, v := range []int{8, 3, 4, 1, 10} {
+		s2.Insert(v)
+	}
+
+	err := s1.Meld(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !s2.Empty() || s2.n != 0 {
+		t.Fatal("s2 should be empty")
+	}
+
+	if s1.n != 10 {
+		t.Fatal("s1.n should be 10, got", s1.n)
+	}
+
+	if y, _ := s1.Min(); y != 1 {
+		t.Fatalf("got: %d, expect: 1", y)
+	}
+
+	for ans := 1; !s1.Empty(); ans++ {
+		v, _ := s1.DeleteMin()
+		if v != ans {
+			t.Fatalf("got %d, expect %d", v, ans)
+		}
+	}
+
+	if !s1.Empty() || s1.n != 0 {
+		t.Fatal("s1 should be empty after delete elements")
+	}
+}
+
+func TestStrictFibonacciHeap_Meld3(t *testing.T) {
+	s1 := StrictFibonacciHeap{}
+	var m MeldablePQ
+
+	err := s1.Meld(m)
+	if err == nil {
+		t.Fatal("should report error when other is not Strict Fibonacci Heap")
+	}
+}
+
+func TestStrictFibonacciHeap_Delete(t *testing.T) {
+	s := StrictFibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = s.Insert(v)
+	}
+
+	n := 8
+	for _, v := range []int{1, 8, 4, 2, 3} {
+		p, err := s.Delete(hd[v])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != v {
+			t.Fatalf("got: %d, expect: %d", p, v)
+		}
+		if s.n != n {
+			t.Fatal("s.n should be", n)
+		}
+		n--
+	}
+
+	if p, _ := s.Min(); p != 5 {
+		t.Fatalf("got: %d, expect: 5", p)
+	}
+
+	for _, v := range []int{6, 5, 9, 7} {
+		p, err := s.Delete(hd[v])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != v {
+			t.Fatalf("got: %d, expect: %d", p, v)
+		}
+		if s.n != n {
+			t.Fatal("s.n should be", n)
+		}
+		n--
+	}
+
+	if !s.Empty() {
+		t.Fatal("s should be empty: ", s.root.item.data)
+	}
+}
+
+func TestStrictFibonacciHeap_Delete2(t *testing.T) {
+	s := StrictFibonacciHeap{}
+	fn := &sfItem{data: 0}
+	_, err := s.Delete(fn)
+	if err == nil {
+		t.Fatal("should report empty deletion")
+	}
+
+	s.Insert(5)
+
+	bn := &bHeapNode{data: 5}
+	_, err = s.Delete(bn)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+}
+
+func TestStrictFibonacciHeap_RandomDelete(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	s := StrictFibonacciHeap{}
+	hd := make([]DataNode, 130)
+
+	insert := rng.Perm(128)
+	t.Logf("insert values: %v", insert)
+	for _, v := range insert {
+		hd[v] = s.Insert(v)
+	}
+	min, err := s.DeleteMin()
+	if err != nil {
+		t.Fatal(s)
+	}
+	if min != 0 {
+		t.Fatalf("got: %d, expect: %d", min, 0)
+	}
+
+	n := 127
+	deletion := rng.Perm(128)
+	t.Logf("delete values: %v", deletion)
+	for _, v := range deletion {
+		if v == 0 || hd[v] == nil {
+			continue
+		}
+
+		p, err := s.Delete(hd[v])
+		n--
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != v {
+			t.Fatalf("got: %d, expect: %d", p, v)
+		}
+		if s.n != n {
+			t.Fatal("s.n should be", n)
+		}
+	}
+
+	if !s.Empty() {
+		t.Fatal("s should be empty: ", s.root.item.data)
+	}
+}
+
+func TestStrictFibonacciHeap_DecreaseKey(t *testing.T) {
+	s := StrictFibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = s.Insert(v)
+	}
+
+	for _, v := range []int{1, 8, 4, 2, 3} {
+		err := s.DecreaseKey(hd[v], -v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if p, _ := s.DeleteMin(); p != -8 {
+		t.Fatalf("got: %d, expect: -8", p)
+	}
+
+	for _, ans := range []int{-4, -3, -2, -1, 5, 6, 7, 9} {
+		p, err := s.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !s.Empty() {
+		t.Fatal("s should be empty: ", s.root.item.data)
+	}
+}
+
+func TestStrictFibonacciHeap_DecreaseKey2(t *testing.T) {
+	s := StrictFibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6} {
+		hd[v] = s.Insert(v)
+	}
+
+	err := s.DecreaseKey(hd[5], 10)
+	if err == nil {
+		t.Fatal("increase a key is not valid")
+	}
+
+	err = s.DecreaseKey(&bHeapNode{data: 5}, 1)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+}
+
+func TestStrictFibonacciHeap_RandomDecreaseKey(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	s := StrictFibonacciHeap{}
+	hd := make([]DataNode, 130)
+
+	insert := rng.Perm(128)
+	t.Logf("insert values: %v", insert)
+	for _, v := range insert {
+		hd[v] = s.Insert(v)
+	}
+
+	min, err := s.DeleteMin()
+	if err != nil {
+		t.Fatal(s)
+	}
+	if min != 0 {
+		t.Fatalf("got: %d, expect: %d", min, 0)
+	}
+
+	decrement := rng.Perm(128)
+	t.Logf("decrease values: %v", decrement)
+	for _, v := range decrement {
+		if v == 0 || hd[v] == nil {
+			continue
+		}
+
+		err := s.DecreaseKey(hd[v], -v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for ans := -127; ans < 0; ans++ {
+		p, err := s.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !s.Empty() {
+		t.Fatal("s should be empty: ", s.root.item.data)
+	}
+}
+
+// sfStats collects the quantities bounded by the invariants of the heap.
+type sfStats struct {
+	nodes, activeRoots, loss, rootDegree int
+}
+
+func checkSFSubtree(t *testing.T, s *StrictFibonacciHeap, x *sfHeapNode, st *sfStats) {
+	st.nodes++
+	if x.item.node != x {
+		t.Fatalf("item %d does not point back to its node", x.item.data)
+	}
+
+	// children: active ones first, then passive ones
+	activeChildren, seenPassive := 0, false
+	for c := x.child; c != nil; {
+		if c.parent != x || c.right.left != c {
+			t.Fatalf("malformed children list of %d", x.item.data)
+		}
+		if !x.less(c) {
+			t.Fatalf("heap order is violated: %d > %d", x.item.data, c.item.data)
+		}
+		if c.isActive() {
+			if seenPassive && x != s.root {
+				t.Fatalf("active child %d is on the right of a passive one", c.item.data)
+			}
+			activeChildren++
+		} else {
+			seenPassive = true
+		}
+		checkSFSubtree(t, s, c, st)
+
+		if c = c.right; c == x.child {
+			break
+		}
+	}
+
+	if !x.isActive() {
+		return
+	}
+	if x.rank.rank != activeChildren {
+		t.Fatalf("rank of %d is %d, but it has %d active children", x.item.data, x.rank.rank, activeChildren)
+	}
+	switch {
+	case x.isActiveRoot():
+		st.activeRoots++
+		if x.loss != 0 || x.fix != sfFixActiveRoot || x.fixRank != x.rank {
+			t.Fatalf("active root %d is not in the fix-list", x.item.data)
+		}
+	case x.loss == 1:
+		if x.fix != sfFixLossOne || x.fixRank != x.rank {
+			t.Fatalf("node %d of loss one is not in the fix-list", x.item.data)
+		}
+	case x.loss >= 2:
+		if x.fix != sfFixLossTwo {
+			t.Fatalf("node %d of loss %d is not in the fix-list", x.item.data, x.loss)
+		}
+	default:
+		if x.fix != sfFixNone {
+			t.Fatalf("node %d should not be in the fix-list", x.item.data)
+		}
+	}
+	st.loss += x.loss
+}
+
+func checkStrictFibonacciHeap(t *testing.T, s *StrictFibonacciHeap) sfStats {
+	var st sfStats
+	if s.Empty() {
+		return st
+	}
+
+	r := s.root
+	if r.isActive() || r.parent != nil {
+		t.Fatal("root should be passive and has no parent")
+	}
+	checkSFSubtree(t, s, r, &st)
+	if st.nodes != s.n {
+		t.Fatalf("got %d nodes, expect %d", st.nodes, s.n)
+	}
+
+	// children of the root: active, passive non-linkable, passive linkable
+	kind, first := 0, true
+	for c := r.child; c != nil; {
+		k := 2
+		if c.isActive() {
+			k = 0
+		} else if !c.linkable() {
+			k = 1
+		}
+		if k < kind {
+			t.Fatalf("children of the root are out of order at %d", c.item.data)
+		}
+		if k > 0 && first {
+			if s.rootPassive != c {
+				t.Fatal("rootPassive is not the leftmost passive child")
+			}
+			first = false
+		}
+		kind = k
+		st.rootDegree++
+
+		if c = c.right; c == r.child {
+			break
+		}
+	}
+	if first && s.rootPassive != nil {
+		t.Fatal("rootPassive should be nil")
+	}
+
+	qLen := 0
+	for x := s.q; x != nil; {
+		if x == r || x.qnext.qprev != x {
+			t.Fatal("malformed queue")
+		}
+		qLen++
+		if x = x.qnext; x == s.q {
+			break
+		}
+	}
+	if qLen != s.n-1 {
+		t.Fatalf("queue has %d nodes, expect %d", qLen, s.n-1)
+	}
+
+	for i, head := range s.pairs {
+		for r := head; r != nil; {
+			if cnt := []int{r.nRoots, r.nLossOne}[i]; cnt < 2 {
+				t.Fatalf("rank %d in the fix-list has only %d nodes", r.rank, cnt)
+			}
+			if r = r.pair[i].next; r == head {
+				break
+			}
+		}
+	}
+
+	return st
+}
+
+func TestStrictFibonacciHeap_RandomOperations(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	var heaps [4]StrictFibonacciHeap
+	live := make([]map[DataNode]bool, len(heaps))
+	hd := make([][]DataNode, len(heaps))
+	for i := range live {
+		live[i] = map[DataNode]bool{}
+	}
+
+	for i := 0; i < 20000; i++ {
+		k := rng.Intn(len(heaps))
+		s := &heaps[k]
+
+		switch op := rng.Intn(20); {
+		case op < 8 || s.Empty():
+			h := s.Insert(rng.Intn(1 << 12))
+			hd[k] = append(hd[k], h)
+			live[k][h] = true
+		case op < 13:
+			h := hd[k][rng.Intn(len(hd[k]))]
+			if !live[k][h] {
+				continue
+			}
+			if err := s.DecreaseKey(h, h.Data()-rng.Intn(1<<8)); err != nil {
+				t.Fatal(err)
+			}
+		case op < 15:
+			h := hd[k][rng.Intn(len(hd[k]))]
+			if !live[k][h] {
+				continue
+			}
+			p, err := s.Delete(h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p != h.Data() {
+				t.Fatalf("got: %d, expect: %d", p, h.Data())
+			}
+			delete(live[k], h)
+		case op < 19:
+			p, err := s.DeleteMin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want DataNode
+			for h := range live[k] {
+				if h.Data() < p {
+					t.Fatalf("got: %d, but %d is smaller", p, h.Data())
+				}
+				if h.(*sfItem).node == nil {
+					want = h
+				}
+			}
+			delete(live[k], want)
+		default:
+			j := rng.Intn(len(heaps))
+			if j == k {
+				continue
+			}
+			if err := s.Meld(&heaps[j]); err != nil {
+				t.Fatal(err)
+			}
+			for h := range live[j] {
+				live[k][h] = true
+			}
+			hd[k] = append(hd[k], hd[j]...)
+			live[j], hd[j] = map[DataNode]bool{}, nil
+		}
+
+		st := checkStrictFibonacciHeap(t, s)
+		if s.n > 0 {
+			bound := 2*int(math.Log2(float64(s.n))) + 6
+			if st.activeRoots > bound+1 || st.loss > bound+1 {
+				t.Fatalf("%d active roots and loss %d exceed the bound %d", st.activeRoots, st.loss, bound+1)
+			}
+			if st.rootDegree > bound+3 {
+				t.Fatalf("root degree %d exceeds the bound %d", st.rootDegree, bound+3)
+			}
+		}
+	}
+}
+
+func TestStrictFibonacciHeap_IncreaseKey(t *testing.T) {
+	s := StrictFibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = s.Insert(v)
+	}
+
+	for _, v := range []int{1, 8, 4, 2, 3} {
+		err := s.IncreaseKey(hd[v], v+10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hd[v].Data() != v+10 {
+			t.Fatalf("got: %d, expect: %d", hd[v].Data(), v+10)
+		}
+	}
+
+	// the handles stay valid after the keys are increased
+	if err := s.DecreaseKey(hd[8], 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ans := range []int{0, 5, 6, 7, 9, 11, 12, 13, 14} {
+		p, err := s.DeleteMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != ans {
+			t.Errorf("got: %d, expect: %d", p, ans)
+		}
+	}
+
+	if !s.Empty() {
+		t.Fatal("s should be empty")
+	}
+}
+
+func TestStrictFibonacciHeap_IncreaseKey2(t *testing.T) {
+	s := StrictFibonacciHeap{}
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6} {
+		hd[v] = s.Insert(v)
+	}
+
+	err := s.IncreaseKey(hd[5], 1)
+	if err == nil {
+		t.Fatal("decrease a key is not valid")
+	}
+
+	err = s.IncreaseKey(&fHeapNode{data: 5}, 10)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+
+	err = s.UpdateKey(&fHeapNode{data: 5}, 10)
+	if err == nil {
+		t.Fatal("should report incorrect type")
+	}
+}
+
+func TestStrictFibonacciHeap_RandomUpdateKey(t *testing.T) {
+	seed := time.Now().UTC().UnixNano()
+	t.Logf("Random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	s := StrictFibonacciHeap{}
+	live := map[DataNode]bool{}
+	var hd []DataNode
+
+	// keys are kept distinct so that the popped handle is known
+	for i := 0; i < 4096; i++ {
+		switch op := rng.Intn(10); {
+		case op < 4 || len(live) == 0:
+			h := s.Insert(rng.Intn(1<<12)<<12 | i)
+			hd = append(hd, h)
+			live[h] = true
+		case op < 8:
+			h := hd[rng.Intn(len(hd))]
+			if !live[h] {
+				continue
+			}
+			key := rng.Intn(1<<12)<<12 | i
+			if err := s.UpdateKey(h, key); err != nil {
+				t.Fatal(err)
+			}
+			if h.Data() != key {
+				t.Fatalf("got: %d, expect: %d", h.Data(), key)
+			}
+		default:
+			p, err := s.DeleteMin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want DataNode
+			for h := range live {
+				if h.Data() < p {
+					t.Fatalf("got: %d, but %d is smaller", p, h.Data())
+				}
+				if h.Data() == p {
+					want = h
+				}
+			}
+			delete(live, want)
+		}
+		checkStrictFibonacciHeap(t, &s)
+	}
+}
+
+func TestStrictFibonacciHeap_MinNode(t *testing.T) {
+	s := StrictFibonacciHeap{}
+	if _, err := s.MinNode(); err == nil {
+		t.Fatal("should report that s is empty")
+	}
+
+	hd := make([]DataNode, 10)
+	for _, v := range []int{5, 2, 7, 6, 9, 1, 8, 4, 3} {
+		hd[v] = s.Insert(v)
+	}
+
+	for ans := 1; ans < 10; ans++ {
+		h, err := s.MinNode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != hd[ans] {
+			t.Fatalf("got: %d, expect: %d", h.Data(), ans)
+		}
+		if k, err := Key(h); err != nil || k != ans {
+			t.Fatalf("got: %d (%v), expect: %d", k, err, ans)
+		}
+
+		if _, err := s.DeleteMin(); err != nil {
+			t.Fatal(err)
+		}
+		if Valid(h) {
+			t.Fatal("the popped node should be invalid")
+		}
+		if _, err := Key(h); err == nil {
+			t.Fatal("should report that the node is not in the queue")
+		}
+	}
+}